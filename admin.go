@@ -0,0 +1,274 @@
+package multilog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxAdminBody is the largest request body accepted by the admin handler.
+const maxAdminBody = 1 << 20
+
+// adminHandler is the http.Handler returned by AdminHandler.
+type adminHandler struct {
+	m *Multilog // m is the dispatcher whose levels are served.
+
+	mu       sync.Mutex                 // mu guards the pending revert.
+	timer    *time.Timer                // timer reverts the levels, nil when no revert is pending.
+	pending  uint64                     // pending identifies the pending revert.
+	revertAt time.Time                  // revertAt is when the pending revert runs.
+	baseline map[*Levels]levelsSnapshot // baseline are the levels to revert to.
+}
+
+// levelsSnapshot is the configuration of a Levels at some point in time.
+type levelsSnapshot struct {
+	base      LogLevel
+	overrides map[string]LogLevel
+}
+
+// adminLevels is the JSON representation of a Levels.
+type adminLevels struct {
+	Level  LogLevel            `json:"level"`
+	Groups map[string]LogLevel `json:"groups"`
+}
+
+// adminState is the JSON document returned by the admin handler.
+type adminState struct {
+	adminLevels
+	Sinks    map[LogMethod]adminLevels `json:"sinks"`
+	RevertAt *time.Time                `json:"revert_at,omitempty"`
+}
+
+// adminLevelsUpdate is the JSON representation of a change to a Levels. A
+// null group level removes the override of the group pattern.
+type adminLevelsUpdate struct {
+	Level  *LogLevel            `json:"level"`
+	Groups map[string]*LogLevel `json:"groups"`
+}
+
+// adminUpdate is the JSON document accepted by the admin handler.
+type adminUpdate struct {
+	adminLevelsUpdate
+	Sinks map[LogMethod]adminLevelsUpdate `json:"sinks"`
+	TTL   string                          `json:"ttl"`
+}
+
+// AdminHandler returns an http.Handler that reads and changes the levels of
+// the dispatcher and of its registered loggers at runtime.
+//
+// GET responds with the current levels as JSON:
+//
+//	{
+//	  "level": "INFO",
+//	  "groups": {"db.*": "TRACE"},
+//	  "sinks": {"console": {"level": "TRACE", "groups": {}}},
+//	  "revert_at": "2025-01-01T12:15:00Z"
+//	}
+//
+// The level of a sink is the base of its CustomLogger.Levels, which the
+// built-in loggers set from their Level argument.
+//
+// PUT accepts a document of the same shape in which every member is optional,
+// and applies only the members it holds. Levels are given by name, as parsed
+// by ParseLevel. A null group level removes the override, and "ttl" is a
+// duration such as "15m" after which the levels are reverted to what they were
+// before the change. While a revert is pending, another PUT with a TTL
+// postpones it and a PUT without one cancels it. A document naming an
+// unregistered logger or holding an invalid group pattern is rejected without
+// applying any of it.
+//
+// The handler does not authenticate its requests, so it must only be served
+// on a private address or behind an authenticating middleware.
+//
+// Returns:
+//   - http.Handler: The admin handler.
+func (m *Multilog) AdminHandler() http.Handler {
+	return &adminHandler{m: m}
+}
+
+// AdminHandler returns an http.Handler that reads and changes the levels of
+// the default Multilog and of its registered loggers at runtime, as described
+// for (*Multilog).AdminHandler.
+//
+// The handler is bound to the dispatcher that is the default when it is created.
+//
+// Returns:
+//   - http.Handler: The admin handler.
+func AdminHandler() http.Handler {
+	return Default().AdminHandler()
+}
+
+// ServeHTTP implements http.Handler.
+func (h *adminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.respond(w, http.StatusOK, h.state())
+	case http.MethodPut:
+		if err := h.update(w, r); err != nil {
+			h.respond(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		h.respond(w, http.StatusOK, h.state())
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		h.respond(w, http.StatusMethodNotAllowed, map[string]string{"error": fmt.Sprintf("method %s not allowed", r.Method)})
+	}
+}
+
+// update decodes a change from the request and applies it.
+func (h *adminHandler) update(w http.ResponseWriter, r *http.Request) error {
+	var update adminUpdate
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&update); err != nil {
+		return fmt.Errorf("error decoding levels: %w", err)
+	}
+
+	var ttl time.Duration
+	if update.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(update.TTL); err != nil {
+			return fmt.Errorf("error parsing ttl: %w", err)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("ttl %s is not positive", update.TTL)
+		}
+	}
+
+	// Every target is resolved and every pattern checked before anything is
+	// applied, so that a rejected document changes nothing.
+	targets := map[*Levels]adminLevelsUpdate{h.m.levels: update.adminLevelsUpdate}
+	sinks := h.m.sinkLevels()
+	for method, levelsUpdate := range update.Sinks {
+		levels, ok := sinks[method]
+		if !ok {
+			return fmt.Errorf("no logger registered for log method %s", method)
+		}
+		targets[levels] = levelsUpdate
+	}
+	for _, levelsUpdate := range targets {
+		for pattern, level := range levelsUpdate.Groups {
+			if level == nil {
+				continue
+			}
+			if err := checkPattern(pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if ttl > 0 {
+		if h.baseline == nil {
+			h.baseline = make(map[*Levels]levelsSnapshot)
+		}
+		for levels := range targets {
+			if _, ok := h.baseline[levels]; !ok {
+				h.baseline[levels] = levelsSnapshot{base: levels.Base(), overrides: levels.Overrides()}
+			}
+		}
+	}
+
+	for levels, levelsUpdate := range targets {
+		if levelsUpdate.Level != nil {
+			levels.SetBase(*levelsUpdate.Level)
+		}
+		for pattern, level := range levelsUpdate.Groups {
+			if level == nil {
+				levels.Remove(pattern)
+				continue
+			}
+			if err := levels.Set(pattern, *level); err != nil {
+				return err
+			}
+		}
+	}
+
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	if ttl == 0 {
+		h.baseline = nil
+		return nil
+	}
+
+	h.pending++
+	pending := h.pending
+	h.timer = time.AfterFunc(ttl, func() { h.revert(pending) })
+	h.revertAt = time.Now().Add(ttl)
+
+	return nil
+}
+
+// revert restores the baseline levels, unless the revert that is due has
+// since been postponed or cancelled.
+func (h *adminHandler) revert(pending uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.timer == nil || h.pending != pending {
+		return
+	}
+
+	for levels, snapshot := range h.baseline {
+		levels.reset(snapshot.base, snapshot.overrides)
+	}
+	h.timer = nil
+	h.baseline = nil
+}
+
+// state returns the current levels.
+func (h *adminHandler) state() *adminState {
+	state := &adminState{
+		adminLevels: newAdminLevels(h.m.levels),
+		Sinks:       make(map[LogMethod]adminLevels),
+	}
+	for method, levels := range h.m.sinkLevels() {
+		state.Sinks[method] = newAdminLevels(levels)
+	}
+
+	h.mu.Lock()
+	if h.timer != nil {
+		revertAt := h.revertAt.UTC()
+		state.RevertAt = &revertAt
+	}
+	h.mu.Unlock()
+
+	return state
+}
+
+// respond writes a JSON response.
+func (h *adminHandler) respond(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(body)
+}
+
+// newAdminLevels returns the JSON representation of a Levels.
+func newAdminLevels(levels *Levels) adminLevels {
+	return adminLevels{
+		Level:  levels.Base(),
+		Groups: levels.Overrides(),
+	}
+}
+
+// sinkLevels returns the levels of every registered logger keyed by log method.
+func (m *Multilog) sinkLevels() map[LogMethod]*Levels {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	levels := make(map[LogMethod]*Levels, len(m.loggers))
+	for method, s := range m.loggers {
+		levels[method] = s.levels
+	}
+
+	return levels
+}
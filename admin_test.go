@@ -0,0 +1,133 @@
+package multilog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveAdmin sends a request to the admin handler and decodes the response.
+func serveAdmin(t *testing.T, h http.Handler, method string, body string) (int, map[string]any) {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, "/levels", strings.NewReader(body)))
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("error decoding response %q: %v", w.Body.String(), err)
+	}
+
+	return w.Code, got
+}
+
+func TestAdminHandler(t *testing.T) {
+	m := NewMultilog(&NewMultilogArgs{Level: INFO})
+	m.RegisterLogger("memory", &CustomLogger{Log: func(*Entry) error { return nil }})
+	h := m.AdminHandler()
+
+	code, got := serveAdmin(t, h, http.MethodGet, "")
+	if code != http.StatusOK || got["level"] != "INFO" {
+		t.Fatalf("unexpected GET response %d: %v", code, got)
+	}
+
+	code, got = serveAdmin(t, h, http.MethodPut, `{"level": "warn", "groups": {"db.*": "trace"}, "sinks": {"memory": {"level": "error"}}}`)
+	if code != http.StatusOK {
+		t.Fatalf("unexpected PUT response %d: %v", code, got)
+	}
+	if m.Levels().Base() != WARN || m.Levels().Level("db.query") != TRACE {
+		t.Errorf("the dispatcher levels were not updated: %v", m.Levels().Overrides())
+	}
+	if sink := got["sinks"].(map[string]any)["memory"].(map[string]any); sink["level"] != "ERROR" {
+		t.Errorf("the sink levels were not updated: %v", sink)
+	}
+
+	// A null level removes the override.
+	serveAdmin(t, h, http.MethodPut, `{"groups": {"db.*": null}}`)
+	if len(m.Levels().Overrides()) != 0 {
+		t.Errorf("the override was not removed: %v", m.Levels().Overrides())
+	}
+
+	for _, body := range []string{
+		`{"sinks": {"missing": {"level": "TRACE"}}}`,
+		`{"level": "TRACE", "groups": {"re:(": "TRACE"}}`,
+		`{"level": "LOUD"}`,
+		`{"level": 16}`,
+		`{"level": 4}`,
+		`{"ttl": "-1m"}`,
+		`{"unknown": 1}`,
+		`not json`,
+	} {
+		if code, got := serveAdmin(t, h, http.MethodPut, body); code != http.StatusBadRequest || got["error"] == nil {
+			t.Errorf("expected a bad request for %s, got %d: %v", body, code, got)
+		}
+	}
+	if m.Levels().Base() != WARN {
+		t.Error("a rejected document should not change the levels")
+	}
+
+	if code, _ := serveAdmin(t, h, http.MethodPost, ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("unexpected POST response %d", code)
+	}
+}
+
+func TestAdminHandler_TTL(t *testing.T) {
+	m := NewMultilog(&NewMultilogArgs{Level: INFO})
+	m.Levels().Set("http", ERROR)
+	h := m.AdminHandler()
+
+	_, got := serveAdmin(t, h, http.MethodPut, `{"level": "DEBUG", "groups": {"http": "DEBUG"}, "ttl": "50ms"}`)
+	if got["revert_at"] == nil {
+		t.Errorf("expected a pending revert: %v", got)
+	}
+	// A second change within the TTL postpones the revert to the original levels.
+	serveAdmin(t, h, http.MethodPut, `{"groups": {"db": "TRACE"}, "ttl": "100ms"}`)
+	if m.Levels().Base() != DEBUG || m.Levels().Level("db") != TRACE {
+		t.Fatalf("the levels were not updated: %d %v", m.Levels().Base(), m.Levels().Overrides())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for m.Levels().Base() != INFO && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if overrides := m.Levels().Overrides(); m.Levels().Base() != INFO || len(overrides) != 1 || overrides["http"] != ERROR {
+		t.Errorf("the levels were not reverted: %d %v", m.Levels().Base(), overrides)
+	}
+	if _, got := serveAdmin(t, h, http.MethodGet, ""); got["revert_at"] != nil {
+		t.Errorf("expected no pending revert: %v", got)
+	}
+
+	// A change without a TTL cancels the pending revert.
+	serveAdmin(t, h, http.MethodPut, `{"level": "DEBUG", "ttl": "20ms"}`)
+	serveAdmin(t, h, http.MethodPut, `{"level": "TRACE"}`)
+	time.Sleep(60 * time.Millisecond)
+	if m.Levels().Base() != TRACE {
+		t.Errorf("the cancelled revert was applied: %d", m.Levels().Base())
+	}
+}
+
+func TestAdminHandler_Sink(t *testing.T) {
+	var buf bytes.Buffer
+	m := NewMultilog(&NewMultilogArgs{Level: TRACE})
+	m.RegisterLogger(LoggerConsole, NewConsoleLogger(&NewConsoleLoggerArgs{Format: FormatText, Level: INFO, Output: &buf, Color: PtrBool(false)}))
+	h := m.AdminHandler()
+
+	_, got := serveAdmin(t, h, http.MethodGet, "")
+	if sink := got["sinks"].(map[string]any)["console"].(map[string]any); sink["level"] != "INFO" {
+		t.Errorf("unexpected sink levels %v", sink)
+	}
+
+	m.Debug("api", "before")
+	if code, got := serveAdmin(t, h, http.MethodPut, `{"sinks": {"console": {"level": "DEBUG"}}, "ttl": "1m"}`); code != http.StatusOK {
+		t.Fatalf("unexpected PUT response %d: %v", code, got)
+	}
+	m.Debug("api", "after")
+
+	if out := buf.String(); strings.Contains(out, "before") || !strings.Contains(out, "after") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
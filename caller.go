@@ -0,0 +1,113 @@
+package multilog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// callerKey is the key of the field holding the file and line of the caller.
+	callerKey = "caller"
+	// functionKey is the key of the field holding the function of the caller.
+	functionKey = "function"
+	// stackKey is the key of the field holding the stack trace.
+	stackKey = "stack"
+)
+
+// maxStackDepth is the maximum number of frames captured.
+const maxStackDepth = 64
+
+// internalPrefixes are the prefixes of the functions skipped to find the
+// caller: those of this package, such as the log.go wrappers, and those of
+// log/slog for the entries logged through the SlogHandler.
+var internalPrefixes = []string{
+	"github.com/mateothegreat/multilog.",
+	"log/slog.",
+}
+
+// callerFields returns the caller, function and stack fields of an entry
+// logged at the given level, as configured by the Caller, CallerSkip and
+// StackLevel arguments.
+func (m *Multilog) callerFields(level LogLevel) []Field {
+	stack := m.args.StackLevel != nil && level >= *m.args.StackLevel
+	if !m.args.Caller && !stack {
+		return nil
+	}
+
+	var pcs [maxStackDepth]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+
+	// The frames of this package and of log/slog are skipped whatever the
+	// wrapper the message was logged with, and then CallerSkip more.
+	skip := m.args.CallerSkip
+	found := false
+	var fields []Field
+	var trace strings.Builder
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		if !found && internal(frame) {
+			continue
+		}
+		if !found && skip > 0 {
+			skip--
+			continue
+		}
+
+		if !found && m.args.Caller {
+			fields = append(fields,
+				String(callerKey, frame.File+":"+strconv.Itoa(frame.Line)),
+				String(functionKey, frame.Function),
+			)
+		}
+		found = true
+		if !stack {
+			break
+		}
+
+		if trace.Len() > 0 {
+			trace.WriteByte('\n')
+		}
+		trace.WriteString(frame.Function)
+		trace.WriteString("\n\t")
+		trace.WriteString(frame.File)
+		trace.WriteByte(':')
+		trace.WriteString(strconv.Itoa(frame.Line))
+	}
+
+	if stack {
+		fields = append(fields, String(stackKey, trace.String()))
+	}
+
+	return fields
+}
+
+// internal reports whether a frame belongs to this package, other than its
+// tests, or to log/slog.
+func internal(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shortCaller returns the last directory and the file name of a caller, such
+// as "api/handler.go:42" for "/src/app/api/handler.go:42".
+func shortCaller(caller string) string {
+	i := strings.LastIndexByte(caller, '/')
+	if i < 0 {
+		return caller
+	}
+	if j := strings.LastIndexByte(caller[:i], '/'); j >= 0 {
+		return caller[j+1:]
+	}
+
+	return caller
+}
@@ -0,0 +1,170 @@
+package multilog
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newCallerMultilog returns a Multilog with the given arguments and the
+// function returning the last entry logged to it.
+func newCallerMultilog(t *testing.T, args *NewMultilogArgs) (*Multilog, func() *Entry) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var last *Entry
+	m := NewMultilog(args)
+	if err := m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			mu.Lock()
+			defer mu.Unlock()
+			last = entry
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	return m, func() *Entry {
+		mu.Lock()
+		defer mu.Unlock()
+		return last
+	}
+}
+
+// fieldValue returns the string value of the field with the given key.
+func fieldValue(entry *Entry, key string) (string, bool) {
+	for _, f := range entry.Fields {
+		if f.Key == key {
+			return f.String, true
+		}
+	}
+
+	return "", false
+}
+
+// logWrapper logs through one more frame, as a wrapper of this package would.
+func logWrapper(m *Multilog, message string) {
+	m.Info("", message)
+}
+
+func TestCaller(t *testing.T) {
+	m, last := newCallerMultilog(t, &NewMultilogArgs{Level: TRACE, Caller: true})
+	previous := Default()
+	SetDefault(m)
+	defer SetDefault(previous)
+
+	ctx := context.Background()
+	child := m.WithGroup("api")
+	handler := slog.New(NewSlogHandler(&NewSlogHandlerArgs{Multilog: m}))
+
+	for _, tt := range []struct {
+		name string
+		log  func()
+	}{
+		{name: "package", log: func() { Info("", "message") }},
+		{name: "package ctx", log: func() { WarnCtx(ctx, "", "message") }},
+		{name: "package log", log: func() { Log(ERROR, "", "message") }},
+		{name: "multilog", log: func() { m.Debug("", "message") }},
+		{name: "multilog ctx", log: func() { m.LogCtx(ctx, INFO, "", "message") }},
+		{name: "child", log: func() { child.Trace("", "message") }},
+		{name: "child ctx", log: func() { child.ErrorCtx(ctx, "", "message") }},
+		{name: "slog", log: func() { handler.Info("message") }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.log()
+
+			entry := last()
+			caller, ok := fieldValue(entry, callerKey)
+			if !ok {
+				t.Fatalf("no caller in %s", entry.Fields.Text())
+			}
+			if !strings.Contains(caller, "/caller_test.go:") {
+				t.Errorf("unexpected caller %s", caller)
+			}
+			function, _ := fieldValue(entry, functionKey)
+			if !strings.HasPrefix(function, "github.com/mateothegreat/multilog.TestCaller.") {
+				t.Errorf("unexpected function %s", function)
+			}
+			if _, ok := fieldValue(entry, stackKey); ok {
+				t.Error("unexpected stack without a StackLevel")
+			}
+		})
+	}
+}
+
+func TestCaller_Line(t *testing.T) {
+	m, last := newCallerMultilog(t, &NewMultilogArgs{Caller: true})
+
+	_, file, line, _ := runtime.Caller(0)
+	m.Info("", "message")
+
+	want := file + ":" + strconv.Itoa(line+1)
+	if caller, _ := fieldValue(last(), callerKey); caller != want {
+		t.Errorf("got caller %s, want %s", caller, want)
+	}
+}
+
+func TestCaller_Skip(t *testing.T) {
+	m, last := newCallerMultilog(t, &NewMultilogArgs{Caller: true, CallerSkip: 1})
+
+	_, file, line, _ := runtime.Caller(0)
+	logWrapper(m, "message")
+
+	want := file + ":" + strconv.Itoa(line+1)
+	if caller, _ := fieldValue(last(), callerKey); caller != want {
+		t.Errorf("got caller %s, want %s", caller, want)
+	}
+	if function, _ := fieldValue(last(), functionKey); function != "github.com/mateothegreat/multilog.TestCaller_Skip" {
+		t.Errorf("unexpected function %s", function)
+	}
+}
+
+func TestCaller_Disabled(t *testing.T) {
+	m, last := newCallerMultilog(t, nil)
+
+	m.Error("", "message", String("user", "bob"))
+	if got := last().Fields.Text(); got != "{user=bob}" {
+		t.Errorf("unexpected fields %s", got)
+	}
+}
+
+func TestStackLevel(t *testing.T) {
+	m, last := newCallerMultilog(t, &NewMultilogArgs{StackLevel: PtrLevel(ERROR)})
+
+	m.Warn("", "message")
+	if _, ok := fieldValue(last(), stackKey); ok {
+		t.Error("unexpected stack below the StackLevel")
+	}
+
+	m.Error("", "message")
+	stack, ok := fieldValue(last(), stackKey)
+	if !ok {
+		t.Fatal("no stack at the StackLevel")
+	}
+	if !strings.HasPrefix(stack, "github.com/mateothegreat/multilog.TestStackLevel\n\t") {
+		t.Errorf("stack does not start at the caller:\n%s", stack)
+	}
+	if strings.Contains(stack, "multilog.(*Multilog)") {
+		t.Errorf("stack holds frames of the package:\n%s", stack)
+	}
+	if _, ok := fieldValue(last(), callerKey); ok {
+		t.Error("unexpected caller without Caller")
+	}
+}
+
+func TestShortCaller(t *testing.T) {
+	for caller, want := range map[string]string{
+		"/src/app/api/handler.go:42": "api/handler.go:42",
+		"api/handler.go:42":          "api/handler.go:42",
+		"handler.go:42":              "handler.go:42",
+	} {
+		if got := shortCaller(caller); got != want {
+			t.Errorf("shortCaller(%q): got %q, want %q", caller, got, want)
+		}
+	}
+}
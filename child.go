@@ -0,0 +1,197 @@
+package multilog
+
+import "context"
+
+// ChildLogger is a logger with a bound group and bound fields, created with
+// With or WithGroup.
+//
+// The bound group is prepended to the group of every message, separated by a
+// dot, and the bound fields are merged into every entry ahead of the fields
+// passed to the log call. A ChildLogger is immutable and safe for concurrent use.
+type ChildLogger struct {
+	m      *Multilog // m is the dispatcher, nil for the default Multilog.
+	group  string    // group is the bound group name.
+	fields Fields    // fields are the bound fields.
+}
+
+// With returns a child logger that adds the given fields to every message.
+//
+// Arguments:
+//   - fields: The fields to bind.
+//
+// Returns:
+//   - *ChildLogger: The child logger.
+func (m *Multilog) With(fields ...Field) *ChildLogger {
+	return (&ChildLogger{m: m}).With(fields...)
+}
+
+// WithGroup returns a child logger that prefixes the group of every message
+// with the given name.
+//
+// Arguments:
+//   - name: The group name to bind.
+//
+// Returns:
+//   - *ChildLogger: The child logger.
+func (m *Multilog) WithGroup(name string) *ChildLogger {
+	return (&ChildLogger{m: m}).WithGroup(name)
+}
+
+// With returns a child logger of the default Multilog that adds the given
+// fields to every message.
+//
+// The child logger resolves the default Multilog on every call, so it can be
+// created before SetDefault is called.
+//
+// Arguments:
+//   - fields: The fields to bind.
+//
+// Returns:
+//   - *ChildLogger: The child logger.
+func With(fields ...Field) *ChildLogger {
+	return (&ChildLogger{}).With(fields...)
+}
+
+// WithGroup returns a child logger of the default Multilog that prefixes the
+// group of every message with the given name.
+//
+// The child logger resolves the default Multilog on every call, so it can be
+// created before SetDefault is called.
+//
+// Arguments:
+//   - name: The group name to bind.
+//
+// Returns:
+//   - *ChildLogger: The child logger.
+func WithGroup(name string) *ChildLogger {
+	return (&ChildLogger{}).WithGroup(name)
+}
+
+// With returns a child logger that adds the given fields to every message,
+// after the fields already bound to c.
+//
+// Arguments:
+//   - fields: The fields to bind.
+//
+// Returns:
+//   - *ChildLogger: The child logger.
+func (c *ChildLogger) With(fields ...Field) *ChildLogger {
+	if len(fields) == 0 {
+		return c
+	}
+
+	// The fields are copied so that the caller can reuse its slice.
+	bound := make(Fields, 0, len(c.fields)+len(fields))
+	bound = append(bound, c.fields...)
+
+	return &ChildLogger{
+		m:      c.m,
+		group:  c.group,
+		fields: append(bound, fields...),
+	}
+}
+
+// WithGroup returns a child logger whose group is nested under the group
+// bound to c, such that c.WithGroup("api").WithGroup("auth") logs to the
+// group "api.auth".
+//
+// Arguments:
+//   - name: The group name to bind.
+//
+// Returns:
+//   - *ChildLogger: The child logger.
+func (c *ChildLogger) WithGroup(name string) *ChildLogger {
+	if name == "" {
+		return c
+	}
+
+	return &ChildLogger{
+		m:      c.m,
+		group:  joinGroup(c.group, name),
+		fields: c.fields,
+	}
+}
+
+// Trace logs a trace message at the TRACE level. The group is nested under
+// the bound group and may be empty to log to the bound group itself.
+func (c *ChildLogger) Trace(group string, message string, fields ...Field) {
+	c.log(context.Background(), TRACE, group, message, fields)
+}
+
+// Debug logs a debug message at the DEBUG level. The group is nested under
+// the bound group and may be empty to log to the bound group itself.
+func (c *ChildLogger) Debug(group string, message string, fields ...Field) {
+	c.log(context.Background(), DEBUG, group, message, fields)
+}
+
+// Info logs an info message at the INFO level. The group is nested under
+// the bound group and may be empty to log to the bound group itself.
+func (c *ChildLogger) Info(group string, message string, fields ...Field) {
+	c.log(context.Background(), INFO, group, message, fields)
+}
+
+// Warn logs a warn message at the WARN level. The group is nested under
+// the bound group and may be empty to log to the bound group itself.
+func (c *ChildLogger) Warn(group string, message string, fields ...Field) {
+	c.log(context.Background(), WARN, group, message, fields)
+}
+
+// Error logs an error message at the ERROR level. The group is nested under
+// the bound group and may be empty to log to the bound group itself.
+func (c *ChildLogger) Error(group string, message string, fields ...Field) {
+	c.log(context.Background(), ERROR, group, message, fields)
+}
+
+// Fatal logs a fatal message at the FATAL level, shuts the loggers down so
+// that the message is written out and then exits the process with status code 1.
+func (c *ChildLogger) Fatal(group string, message string, fields ...Field) {
+	c.log(context.Background(), FATAL, group, message, fields)
+	c.multilog().exit()
+}
+
+// Log logs a message at the given level, which may be a custom level
+// registered with RegisterLevel. Unlike Fatal, it never exits the process.
+func (c *ChildLogger) Log(level LogLevel, group string, message string, fields ...Field) {
+	c.log(context.Background(), level, group, message, fields)
+}
+
+// multilog returns the dispatcher the child logger logs to.
+func (c *ChildLogger) multilog() *Multilog {
+	if c.m == nil {
+		return Default()
+	}
+
+	return c.m
+}
+
+// log dispatches a message with the bound group and fields.
+func (c *ChildLogger) log(ctx context.Context, level LogLevel, group string, message string, fields []Field) {
+	c.multilog().log(ctx, level, joinGroup(c.group, group), message, c.merge(fields))
+}
+
+// merge returns the bound fields followed by the given fields, allocating
+// only when both are non-empty.
+func (c *ChildLogger) merge(fields []Field) Fields {
+	if len(c.fields) == 0 {
+		return fields
+	}
+	if len(fields) == 0 {
+		return c.fields
+	}
+
+	merged := make(Fields, 0, len(c.fields)+len(fields))
+	merged = append(merged, c.fields...)
+	return append(merged, fields...)
+}
+
+// joinGroup nests a group under a parent group, separated by a dot.
+func joinGroup(parent string, group string) string {
+	switch {
+	case parent == "":
+		return group
+	case group == "":
+		return parent
+	default:
+		return parent + "." + group
+	}
+}
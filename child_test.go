@@ -0,0 +1,58 @@
+package multilog
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestChildLogger(t *testing.T) {
+	var mu sync.Mutex
+	var entries []*Entry
+
+	m := NewMultilog(nil)
+	if err := m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			mu.Lock()
+			defer mu.Unlock()
+			entries = append(entries, entry)
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	api := m.WithGroup("api").With(String("request_id", "abc"))
+	auth := api.WithGroup("auth").With(Int("attempt", 2))
+
+	api.Info("", "request", Int("status", 200))
+	auth.Warn("", "denied")
+	auth.Error("token", "expired", String("user", "bob"))
+
+	tests := []struct {
+		group  string
+		fields string
+	}{
+		{group: "api", fields: "{request_id=abc status=200}"},
+		{group: "api.auth", fields: "{request_id=abc attempt=2}"},
+		{group: "api.auth.token", fields: "{request_id=abc attempt=2 user=bob}"},
+	}
+
+	if len(entries) != len(tests) {
+		t.Fatalf("expected %d entries, got %d", len(tests), len(entries))
+	}
+	for i, tt := range tests {
+		if entries[i].Group != tt.group {
+			t.Errorf("entry %d: expected group %q, got %q", i, tt.group, entries[i].Group)
+		}
+		if got := entries[i].Fields.Text(); got != tt.fields {
+			t.Errorf("entry %d: expected fields %s, got %s", i, tt.fields, got)
+		}
+	}
+
+	// Binding more fields to a child must not leak into its parent or siblings.
+	api.With(String("a", "1"))
+	api.With(String("b", "2")).Info("", "sibling")
+	if got := entries[len(entries)-1].Fields.Text(); got != "{request_id=abc b=2}" {
+		t.Errorf("unexpected sibling fields: %s", got)
+	}
+}
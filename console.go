@@ -1,59 +1,74 @@
 package multilog
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"log/slog"
 	"os"
 	"regexp"
+	"sync"
 
-	"github.com/fatih/color"
+	"github.com/mateothegreat/multilog/console"
 )
 
 // PrettyHandlerOptions defines options for the PrettyHandler.
 type PrettyHandlerOptions struct {
 	SlogOpts slog.HandlerOptions // SlogOpts are the options for the slog.Handler.
+	Theme    *console.Theme      // Theme colors the output. Defaults to console.DarkTheme.
+	Color    *bool               // Color overrides whether the output is colored, detected by console.ColorEnabled when nil.
 }
 
 // PrettyHandler is a custom handler for pretty-printing log messages.
 type PrettyHandler struct {
 	slog.Handler
-	l *log.Logger // l is the standard library logger used for output.
+	mu    sync.Mutex     // mu serializes the writes to out.
+	out   io.Writer      // out receives the lines.
+	theme *console.Theme // theme colors the output.
 }
 
 // Handle processes the log record and outputs it in a pretty format.
 func (h *PrettyHandler) Handle(ctx context.Context, r slog.Record) error {
-	level := fmt.Sprintf("[%s]", r.Level.String())
-
-	switch r.Level {
-	case slog.LevelDebug:
-		level = color.MagentaString(level)
-	case slog.LevelInfo:
-		level = color.BlueString(level)
-	case slog.LevelWarn:
-		level = color.YellowString(level)
-	case slog.LevelError:
-		level = color.RedString(level)
-	}
-
-	fields := make(map[string]interface{}, r.NumAttrs())
+	fields := make(Fields, 0, r.NumAttrs())
 	r.Attrs(func(a slog.Attr) bool {
-		fields[a.Key] = a.Value.Any()
+		fields = appendAttr(fields, a)
 		return true
 	})
 
-	b, err := json.MarshalIndent(fields, "", "  ")
-	if err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	indented := getBuffer()
+	defer putBuffer(indented)
+
+	// Encode the attributes into buf, indent them into indented, and reuse buf
+	// for the line.
+	var err error
+	if *buf, err = fields.AppendJSON(*buf); err != nil {
+		return err
+	}
+	out := bytes.NewBuffer(*indented)
+	if err := json.Indent(out, *buf, "", "  "); err != nil {
 		return err
 	}
+	*indented = out.Bytes()
+
+	line := h.theme.Timestamp.Append((*buf)[:0], r.Time.Format("[15:04:05.000]"))
+	line = append(line, ' ')
+	line = levelColor(h.theme, LevelFromSlog(r.Level)).Append(line, "["+r.Level.String()+"]")
+	line = append(line, ' ')
+	line = h.theme.Message.Append(line, r.Message)
+	line = append(line, ' ')
+	line = h.theme.Value.Append(line, string(*indented))
+	*buf = append(line, '\n')
 
-	timeStr := r.Time.Format("[15:05:05.000]")
-	msg := color.CyanString(r.Message)
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	h.l.Println(timeStr, level, msg, color.WhiteString(string(b)))
+	if _, err := h.out.Write(*buf); err != nil {
+		return fmt.Errorf("error writing record: %w", err)
+	}
 
 	return nil
 }
@@ -62,7 +77,14 @@ func (h *PrettyHandler) Handle(ctx context.Context, r slog.Record) error {
 func NewPrettyHandler(out io.Writer, opts PrettyHandlerOptions) *PrettyHandler {
 	h := &PrettyHandler{
 		Handler: slog.NewJSONHandler(out, &opts.SlogOpts),
-		l:       log.New(out, "", 0),
+		out:     out,
+		theme:   opts.Theme,
+	}
+	if h.theme == nil {
+		h.theme = console.DarkTheme()
+	}
+	if !colorEnabled(opts.Color, out) {
+		h.theme = console.MonochromeTheme()
 	}
 
 	return h
@@ -77,89 +99,125 @@ func NewSlogLogger() *slog.Logger {
 	}))
 }
 
-// ConsoleLogger is a custom logger that uses slog.Logger.
+// ConsoleLogger is a custom logger that writes colored text lines, or entries
+// encoded by an Encoder such as the JSONEncoder, to the console or to any
+// other writer. It does not use the global log package.
 type ConsoleLogger struct {
 	args           *NewConsoleLoggerArgs // args are the arguments for the NewConsoleLogger function.
-	logger         *slog.Logger          // logger is the slog.Logger instance used for logging.
 	filterPatterns []*regexp.Regexp      // filterPatterns are the regex patterns to filter out log messages.
+	mu             sync.Mutex            // mu serializes the writes to the outputs.
+	output         consoleOutput         // output receives the entries below WARN.
+	errorOutput    consoleOutput         // errorOutput receives the WARN and higher entries.
+	layout         *textLayout           // layout is the layout of the text lines.
 }
 
-// Setup initializes the CustomLogger by creating a new slog.Logger.
-func (c *ConsoleLogger) Setup() {
-	// Create a new slog.Logger.
-	c.logger = NewSlogLogger()
+// consoleOutput is a writer together with the theme of the text lines written to it.
+type consoleOutput struct {
+	w     io.Writer      // w is the writer.
+	theme *console.Theme // theme colors the text lines, monochrome when w is not colored.
+}
+
+// Setup initializes the CustomLogger by parsing the layout and compiling the
+// filter drop patterns.
+func (c *ConsoleLogger) Setup() error {
+	layout, err := newTextLayout(c.args)
+	if err != nil {
+		return fmt.Errorf("error parsing layout: %w", err)
+	}
+	c.layout = layout
+
 	// Compile the filter drop patterns into regexp.Regexp instances.
 	for _, pattern := range c.args.FilterDropPatterns {
 		if pattern != nil {
-			c.filterPatterns = append(c.filterPatterns, regexp.MustCompile(*pattern))
+			compiledPattern, err := regexp.Compile(*pattern)
+			if err != nil {
+				return fmt.Errorf("error compiling filter pattern: %w", err)
+			}
+			c.filterPatterns = append(c.filterPatterns, compiledPattern)
 		}
 	}
+
+	return nil
 }
 
-// Log logs a message with the given log level, group, message, and additional data.
-func (c *ConsoleLogger) Log(level LogLevel, group string, message string, v map[string]interface{}) {
-	// Check if the log level is sufficient to log the message.
-	if level < c.args.Level {
-		return // Drop the message if the log level is lower than the configured level.
-	}
+// Log logs an entry with its log level, group, message, and fields.
+func (c *ConsoleLogger) Log(entry *Entry) error {
+	level, group, message := entry.Level, entry.Group, entry.Message
 
 	// Check if the message matches any of the filter drop patterns.
 	for _, pattern := range c.filterPatterns {
 		if pattern.MatchString(group) || pattern.MatchString(message) {
-			return
+			return nil
 		}
 	}
 
-	// Create a new slog.Logger with the group.
-	logger := c.logger.With(slog.String("group", group))
+	out := &c.output
+	if level >= WARN {
+		out = &c.errorOutput
+	}
 
-	// Log the message with the given log level.
-	switch level {
-	case DEBUG:
-		if c.args.Format == FormatJSON {
-			logger.Debug(message, "data", v)
-		} else {
-			log.Printf(color.HiCyanString("[DEBUG]")+" %s: %s %v", color.GreenString(group), color.YellowString(message), colorizeMap(v))
-		}
-	case INFO:
-		if c.args.Format == FormatJSON {
-			logger.Info(message, "data", v)
-		} else {
-			log.Printf(color.HiBlueString("[INFO]")+" %s: %s %v", color.GreenString(group), color.YellowString(message), colorizeMap(v))
-		}
-	case WARN:
-		if c.args.Format == FormatJSON {
-			logger.Warn(message, "data", v)
-		} else {
-			log.Printf(color.HiYellowString("[WARN]")+" %s: %s %v", color.GreenString(group), color.YellowString(message), colorizeMap(v))
-		}
-	case ERROR:
-		if c.args.Format == FormatJSON {
-			logger.Error(message, "data", v)
-		} else {
-			log.Printf(color.HiRedString("[ERROR]")+" %s: %s %v", color.GreenString(group), color.YellowString(message), colorizeMap(v))
-		}
-	case FATAL:
-		if c.args.Format == FormatJSON {
-			logger.Error(message, "data", v)
-		} else {
-			log.Printf(color.HiRedString("[FATAL]")+" %s: %s %v", color.GreenString(group), color.YellowString(message), colorizeMap(v))
-		}
-	default:
-		if c.args.Format == FormatJSON {
-			logger.Info(message, "data", v)
-		} else {
-			log.Printf(color.HiBlueString("[UNKNOWN]")+" %s: %s %v", color.GreenString(group), color.YellowString(message), colorizeMap(v))
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	// Encode the entry with the encoder: the one provided, if any, or the JSON
+	// encoder for the JSON format. Otherwise, format it as a text line colored
+	// by the theme.
+	encoder := c.args.Encoder
+	if encoder == nil && c.args.Format == FormatJSON {
+		encoder = JSONEncoder{}
+	}
+	if encoder != nil {
+		var err error
+		if *buf, err = encoder.Encode(*buf, entry); err != nil {
+			return err
 		}
+	} else {
+		*buf = append(c.layout.append(*buf, out.theme, entry), '\n')
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := out.w.Write(*buf); err != nil {
+		return fmt.Errorf("error writing entry: %w", err)
+	}
+
+	return nil
+}
+
+// levelColor returns the color of a level in a theme, falling back to the
+// color of the built-in level below it for the levels the theme does not name.
+func levelColor(theme *console.Theme, level LogLevel) console.FgBgColor {
+	if color, ok := theme.Level(level.String()); ok {
+		return color
 	}
+
+	color, _ := theme.Level(level.standard().String())
+	return color
 }
 
-func colorizeMap(v map[string]interface{}) map[string]interface{} {
-	colorizedMap := make(map[string]interface{})
-	for key, value := range v {
-		colorizedMap[color.HiBlueString(key)] = color.HiBlackString("%v", value)
+// newConsoleOutput returns an output writing to w with the theme of the
+// arguments, or without colors if w is not colored.
+func newConsoleOutput(args *NewConsoleLoggerArgs, w io.Writer) consoleOutput {
+	theme := args.Theme
+	if theme == nil {
+		theme = console.DarkTheme()
+	}
+	if !colorEnabled(args.Color, w) {
+		theme = console.MonochromeTheme()
 	}
-	return colorizedMap
+
+	return consoleOutput{w: w, theme: theme}
+}
+
+// colorEnabled reports whether the output written to w is colored, as set by
+// the override or else as detected by console.ColorEnabled.
+func colorEnabled(override *bool, w io.Writer) bool {
+	if override != nil {
+		return *override
+	}
+
+	return console.ColorEnabled(w)
 }
 
 // Format is the format of the log that is output.
@@ -172,24 +230,75 @@ const (
 
 // NewConsoleLoggerArgs are the arguments for the NewConsoleLogger function.
 type NewConsoleLoggerArgs struct {
-	// Level is the log level to use.
+	// Level is the base of the levels of the returned CustomLogger, which
+	// group patterns set on its Levels can override in either direction.
 	Level LogLevel
 	// Format is the format of the log that is output.
 	Format Format
+	// Layout is the layout of the text lines, in which the placeholders
+	// {time}, {level}, {group}, {caller}, {message} and {fields} are replaced
+	// by the parts of the entry. Defaults to DefaultConsoleLayout.
+	Layout string
+	// TimeLayout is the Go time layout of the {time} placeholder. Defaults to
+	// DefaultConsoleTimeLayout.
+	TimeLayout string
+	// UTC renders the {time} placeholder in UTC rather than in local time.
+	UTC bool
+	// Align pads the {level} and {group} placeholders to fixed widths, so that
+	// the parts following them line up in columns.
+	Align bool
+	// GroupWidth is the width the {group} placeholder is padded to when Align
+	// is set. Defaults to DefaultGroupWidth.
+	GroupWidth int
+	// FieldFormat is how the {fields} placeholder renders the fields.
+	// Defaults to FieldFormatKeyValue.
+	FieldFormat FieldFormat
+	// CallerPath is how the caller captured with NewMultilogArgs.Caller is
+	// rendered by the {caller} and {fields} placeholders. Defaults to
+	// CallerPathShort.
+	CallerPath CallerPath
+	// Encoder encodes the entries written to the outputs, such as a
+	// LogfmtEncoder. When set, it takes precedence over Format.
+	Encoder Encoder
 	// FilterDropPatterns is a slice of regex patterns to filter out log messages.
 	FilterDropPatterns []*string
+	// Theme colors the text format, such as console.LightTheme or a theme read
+	// with console.LoadTheme. Defaults to console.DarkTheme.
+	Theme *console.Theme
+	// Color overrides whether the text format is colored. When nil, each
+	// output is colored if console.ColorEnabled reports that it should be,
+	// which depends on whether it is a terminal and on the NO_COLOR,
+	// FORCE_COLOR and TERM environment variables.
+	Color *bool
+	// Output is where the entries are written. Defaults to os.Stdout.
+	Output io.Writer
+	// ErrorOutput is where the WARN and higher entries are written, such as
+	// os.Stderr. Defaults to Output.
+	ErrorOutput io.Writer
 }
 
 // NewConsoleLogger creates a new CustomLogger for console logging.
 //
 // Returns a new CustomLogger with the setup and log functions for console logging.
 func NewConsoleLogger(args *NewConsoleLoggerArgs) *CustomLogger {
+	output := args.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	errorOutput := args.ErrorOutput
+	if errorOutput == nil {
+		errorOutput = output
+	}
+
 	logger := &ConsoleLogger{
-		args: args,
+		args:        args,
+		output:      newConsoleOutput(args, output),
+		errorOutput: newConsoleOutput(args, errorOutput),
 	}
 
 	return &CustomLogger{
-		Setup: logger.Setup,
-		Log:   logger.Log,
+		Setup:  logger.Setup,
+		Log:    logger.Log,
+		Levels: NewLevels(args.Level),
 	}
 }
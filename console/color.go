@@ -4,10 +4,16 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"log"
+	"os"
 	"sync"
 )
 
+// Logger is the interface of the loggers wrapped by NewColorLogger, which log
+// alternating keys and values.
+type Logger interface {
+	Log(keyvals ...interface{}) error
+}
+
 // Color represents an ANSI color. The zero value is Default.
 type Color uint8
 
@@ -62,19 +68,142 @@ func init() {
 	}
 }
 
+// colorNames are the names of the colors, as used by String and UnmarshalText.
+var colorNames = [numColors]string{
+	"default",
+	"black",
+	"dark_red",
+	"dark_green",
+	"brown",
+	"dark_blue",
+	"dark_magenta",
+	"dark_cyan",
+	"gray",
+	"dark_gray",
+	"red",
+	"green",
+	"yellow",
+	"blue",
+	"magenta",
+	"cyan",
+	"white",
+}
+
+// String returns the name of the color, such as "dark_red".
+func (c Color) String() string {
+	if c >= numColors {
+		return fmt.Sprintf("Color(%d)", uint8(c))
+	}
+
+	return colorNames[c]
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the name of the color.
+func (c Color) MarshalText() ([]byte, error) {
+	if c >= numColors {
+		return nil, fmt.Errorf("invalid color %d", uint8(c))
+	}
+
+	return []byte(colorNames[c]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the name of a color.
+func (c *Color) UnmarshalText(text []byte) error {
+	for i, name := range colorNames {
+		if string(text) == name {
+			*c = Color(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown color %q", text)
+}
+
 // FgBgColor represents a foreground and background color.
 type FgBgColor struct {
-	Fg, Bg Color
+	Fg Color `json:"fg,omitempty"`
+	Bg Color `json:"bg,omitempty"`
 }
 
 func (c FgBgColor) isZero() bool {
 	return c.Fg == Default && c.Bg == Default
 }
 
+// Append appends s to dst, preceded by the escape codes that set the color and
+// followed by the one that resets it. Nothing but s is appended when both
+// colors are Default.
+func (c FgBgColor) Append(dst []byte, s string) []byte {
+	if c.isZero() {
+		return append(dst, s...)
+	}
+
+	if c.Fg != Default {
+		dst = append(dst, fgColorBytes[c.Fg]...)
+	}
+	if c.Bg != Default {
+		dst = append(dst, bgColorBytes[c.Bg]...)
+	}
+	dst = append(dst, s...)
+
+	return append(dst, resetColorBytes...)
+}
+
+// Sprint returns s wrapped in the escape codes of the color, as appended by Append.
+func (c FgBgColor) Sprint(s string) string {
+	if c.isZero() {
+		return s
+	}
+
+	return string(c.Append(nil, s))
+}
+
+// ColorEnabled reports whether colors should be written to w, which is the
+// case when:
+//   - the FORCE_COLOR environment variable is set to anything but "0" or "false", or else
+//   - the NO_COLOR environment variable is not set to a non-empty value,
+//     the TERM environment variable is not "dumb" and w is a terminal.
+//
+// See https://no-color.org and https://force-color.org.
+//
+// Arguments:
+//   - w: The writer the colors would be written to.
+//
+// Returns:
+//   - bool: Whether colors should be written to w.
+func ColorEnabled(w io.Writer) bool {
+	if force, ok := os.LookupEnv("FORCE_COLOR"); ok {
+		return force != "0" && force != "false"
+	}
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a file that is a character device, such as
+// a terminal, rather than a regular file or a pipe.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
 // NewColorLogger returns a Logger which writes colored logs to w. ANSI color
 // codes for the colors returned by color are added to the formatted output
 // from the Logger returned by newLogger and the combined result written to w.
-func NewColorLogger(w io.Writer, newLogger func(io.Writer) log.Logger, color func(keyvals ...interface{}) FgBgColor) log.Logger {
+//
+// Colors are only added when ColorEnabled reports that w should be colored,
+// and the output of the Logger returned by newLogger is written as is otherwise.
+func NewColorLogger(w io.Writer, newLogger func(io.Writer) Logger, color func(keyvals ...interface{}) FgBgColor) Logger {
 	if color == nil {
 		panic("color func nil")
 	}
@@ -84,18 +213,24 @@ func NewColorLogger(w io.Writer, newLogger func(io.Writer) log.Logger, color fun
 		color:         color,
 		bufPool:       sync.Pool{New: func() interface{} { return &loggerBuf{} }},
 		noColorLogger: newLogger(w),
+		enabled:       ColorEnabled(w),
 	}
 }
 
 type colorLogger struct {
 	w             io.Writer
-	newLogger     func(io.Writer) log.Logger
+	newLogger     func(io.Writer) Logger
 	color         func(keyvals ...interface{}) FgBgColor
 	bufPool       sync.Pool
-	noColorLogger log.Logger
+	noColorLogger Logger
+	enabled       bool // enabled reports whether colors are written to w.
 }
 
 func (l *colorLogger) Log(keyvals ...interface{}) error {
+	if !l.enabled {
+		return l.noColorLogger.Log(keyvals...)
+	}
+
 	color := l.color(keyvals...)
 	if color.isZero() {
 		return l.noColorLogger.Log(keyvals...)
@@ -122,7 +257,7 @@ func (l *colorLogger) Log(keyvals ...interface{}) error {
 
 type loggerBuf struct {
 	buf    *bytes.Buffer
-	logger log.Logger
+	logger Logger
 }
 
 func (l *colorLogger) getLoggerBuf() *loggerBuf {
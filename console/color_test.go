@@ -0,0 +1,101 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// keyvalLogger is a Logger that writes its key/value pairs as key=value.
+type keyvalLogger struct {
+	w io.Writer
+}
+
+func (l keyvalLogger) Log(keyvals ...interface{}) error {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fmt.Fprintf(l.w, "%v=%v ", keyvals[i], keyvals[i+1])
+	}
+	return nil
+}
+
+func TestFgBgColor_Append(t *testing.T) {
+	if got := string((FgBgColor{}).Append(nil, "plain")); got != "plain" {
+		t.Errorf("unexpected default color output %q", got)
+	}
+	if got := (FgBgColor{Fg: DarkRed, Bg: Yellow}).Sprint("x"); got != "\x1b[31m\x1b[43;1mx\x1b[39;49;22m" {
+		t.Errorf("unexpected colored output %q", got)
+	}
+}
+
+func TestNewColorLogger(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "1")
+
+	var out bytes.Buffer
+	logger := NewColorLogger(&out, func(w io.Writer) Logger { return keyvalLogger{w: w} }, func(keyvals ...interface{}) FgBgColor {
+		if keyvals[1] == "error" {
+			return FgBgColor{Fg: Red}
+		}
+		return FgBgColor{}
+	})
+
+	logger.Log("level", "info")
+	logger.Log("level", "error")
+
+	if got := out.String(); got != "level=info \x1b[31;1mlevel=error \x1b[39;49;22m" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestNewColorLogger_Disabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	var out bytes.Buffer
+	logger := NewColorLogger(&out, func(w io.Writer) Logger { return keyvalLogger{w: w} }, func(keyvals ...interface{}) FgBgColor {
+		return FgBgColor{Fg: Red}
+	})
+	logger.Log("level", "error")
+
+	if got := out.String(); got != "level=error " {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, tt := range []struct {
+		name string
+		env  map[string]string
+		w    io.Writer
+		want bool
+	}{
+		{"file", nil, file, false},
+		{"buffer", nil, &bytes.Buffer{}, false},
+		{"forced", map[string]string{"FORCE_COLOR": "1"}, file, true},
+		{"forced over NO_COLOR", map[string]string{"FORCE_COLOR": "true", "NO_COLOR": "1"}, file, true},
+		{"forced off", map[string]string{"FORCE_COLOR": "0"}, file, false},
+		{"NO_COLOR", map[string]string{"NO_COLOR": "1"}, file, false},
+		{"dumb terminal", map[string]string{"TERM": "dumb"}, file, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("FORCE_COLOR", "") // Restores the variable after the test.
+			os.Unsetenv("FORCE_COLOR")
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("TERM", "xterm")
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			if got := ColorEnabled(tt.w); got != tt.want {
+				t.Errorf("ColorEnabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
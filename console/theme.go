@@ -0,0 +1,128 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Theme holds the colors of each part of a log line.
+//
+// A theme is loaded from a JSON file with LoadTheme, in which colors are given
+// by name:
+//
+//	{
+//	  "levels": {"INFO": {"fg": "blue"}, "ERROR": {"fg": "white", "bg": "dark_red"}},
+//	  "group": {"fg": "dark_green"},
+//	  "message": {"fg": "brown"},
+//	  "key": {"fg": "blue"},
+//	  "value": {"fg": "dark_gray"},
+//	  "timestamp": {"fg": "dark_gray"},
+//	  "caller": {"fg": "dark_gray"}
+//	}
+type Theme struct {
+	// Levels are the colors of the level names, keyed by name such as "WARN".
+	Levels map[string]FgBgColor `json:"levels,omitempty"`
+	// Group is the color of the group.
+	Group FgBgColor `json:"group"`
+	// Message is the color of the message.
+	Message FgBgColor `json:"message"`
+	// Key is the color of the field keys.
+	Key FgBgColor `json:"key"`
+	// Value is the color of the field values.
+	Value FgBgColor `json:"value"`
+	// Timestamp is the color of the time.
+	Timestamp FgBgColor `json:"timestamp"`
+	// Caller is the color of the caller.
+	Caller FgBgColor `json:"caller"`
+}
+
+// DarkTheme returns a theme of bright colors, for terminals with a dark background.
+//
+// Returns:
+//   - *Theme: A new dark theme.
+func DarkTheme() *Theme {
+	return &Theme{
+		Levels: map[string]FgBgColor{
+			"TRACE": {Fg: Magenta},
+			"DEBUG": {Fg: Cyan},
+			"INFO":  {Fg: Blue},
+			"WARN":  {Fg: Yellow},
+			"ERROR": {Fg: Red},
+			"FATAL": {Fg: White, Bg: DarkRed},
+		},
+		Group:     FgBgColor{Fg: DarkGreen},
+		Message:   FgBgColor{Fg: Brown},
+		Key:       FgBgColor{Fg: Blue},
+		Value:     FgBgColor{Fg: DarkGray},
+		Timestamp: FgBgColor{Fg: DarkGray},
+		Caller:    FgBgColor{Fg: DarkGray},
+	}
+}
+
+// LightTheme returns a theme of dark colors, for terminals with a light background.
+//
+// Returns:
+//   - *Theme: A new light theme.
+func LightTheme() *Theme {
+	return &Theme{
+		Levels: map[string]FgBgColor{
+			"TRACE": {Fg: DarkMagenta},
+			"DEBUG": {Fg: DarkCyan},
+			"INFO":  {Fg: DarkBlue},
+			"WARN":  {Fg: Brown},
+			"ERROR": {Fg: DarkRed},
+			"FATAL": {Fg: White, Bg: DarkRed},
+		},
+		Group:     FgBgColor{Fg: DarkGreen},
+		Message:   FgBgColor{Fg: Black},
+		Key:       FgBgColor{Fg: DarkBlue},
+		Value:     FgBgColor{Fg: DarkGray},
+		Timestamp: FgBgColor{Fg: Gray},
+		Caller:    FgBgColor{Fg: Gray},
+	}
+}
+
+// MonochromeTheme returns a theme without colors.
+//
+// Returns:
+//   - *Theme: A new monochrome theme.
+func MonochromeTheme() *Theme {
+	return &Theme{}
+}
+
+// LoadTheme reads a theme from a JSON file, as described for Theme. The parts
+// of a log line that the file does not mention are not colored.
+//
+// Arguments:
+//   - path: The path of the file to read.
+//
+// Returns:
+//   - *Theme: The theme read from the file.
+//   - `error` if the file cannot be read or is not a valid theme.
+func LoadTheme(path string) (*Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading theme: %w", err)
+	}
+
+	theme := &Theme{}
+	if err := json.Unmarshal(data, theme); err != nil {
+		return nil, fmt.Errorf("error parsing theme %s: %w", path, err)
+	}
+
+	return theme, nil
+}
+
+// Level returns the color of a level name.
+//
+// Arguments:
+//   - name: The name of the level, such as "WARN".
+//
+// Returns:
+//   - FgBgColor: The color of the level.
+//   - bool: Whether the theme has a color for the level.
+func (t *Theme) Level(name string) (FgBgColor, bool) {
+	color, ok := t.Levels[name]
+	return color, ok
+}
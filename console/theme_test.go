@@ -0,0 +1,29 @@
+package console
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.json")
+	os.WriteFile(path, []byte(`{"levels": {"NOTICE": {"fg": "dark_cyan"}}, "group": {"fg": "green", "bg": "black"}}`), 0o644)
+
+	theme, err := LoadTheme(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if color, ok := theme.Level("NOTICE"); !ok || color.Fg != DarkCyan {
+		t.Errorf("unexpected NOTICE color %+v", color)
+	}
+	if theme.Group != (FgBgColor{Fg: Green, Bg: Black}) || theme.Message != (FgBgColor{}) {
+		t.Errorf("unexpected theme %+v", theme)
+	}
+
+	os.WriteFile(path, []byte(`{"group": {"fg": "purple"}}`), 0o644)
+	if _, err := LoadTheme(path); err == nil || !strings.Contains(err.Error(), "purple") {
+		t.Errorf("expected an error for an unknown color, got %v", err)
+	}
+}
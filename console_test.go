@@ -1,7 +1,14 @@
 package multilog
 
 import (
+	"bytes"
+	"log/slog"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/mateothegreat/multilog/console"
 )
 
 func TestConsoleLogger_Handle(t *testing.T) {
@@ -13,12 +20,145 @@ func TestConsoleLogger_Handle(t *testing.T) {
 		},
 	})
 	logger.Setup()
-	logger.Log(INFO, "test", "test", map[string]interface{}{
-		"foo": "test",
-		"bar": 1,
-	})
-	logger.Log(WARN, "test", "test", map[string]interface{}{
-		"foo": "test",
-		"bar": 1,
-	})
+	logger.Log(&Entry{Level: INFO, Group: "test", Message: "test", Fields: Fields{
+		String("foo", "test"),
+		Int("bar", 1),
+	}})
+	logger.Log(&Entry{Level: WARN, Group: "test", Message: "test", Fields: Fields{
+		String("foo", "test"),
+		Int("bar", 1),
+	}})
+}
+
+func TestPrettyHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewPrettyHandler(&buf, PrettyHandlerOptions{Color: PtrBool(false)}))
+	logger.Warn("message", "user", "bob", slog.Group("request", "id", 42))
+	logger.Info("empty")
+
+	want := "[WARN] message {\n  \"user\": \"bob\",\n  \"request\": {\n    \"id\": 42\n  }\n}\n"
+	lines := strings.SplitN(buf.String(), " ", 2)
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], want) || !strings.HasSuffix(buf.String(), "[INFO] empty {}\n") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestConsoleLogger_LevelNames(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewConsoleLogger(&NewConsoleLoggerArgs{Format: FormatText, Output: &buf})
+	logger.Setup()
+	logger.Log(&Entry{Level: TRACE, Group: "test", Message: "trace"})
+	logger.Log(&Entry{Level: INFO + 1, Group: "test", Message: "custom"})
+
+	if out := buf.String(); !strings.Contains(out, "[TRACE]") || !strings.Contains(out, "[INFO+1]") || strings.Contains(out, "UNKNOWN") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestConsoleLogger_Theme(t *testing.T) {
+	theme := &console.Theme{
+		Levels: map[string]console.FgBgColor{"INFO": {Fg: console.Blue}},
+		Key:    console.FgBgColor{Fg: console.Red},
+	}
+	at := time.Date(2024, 7, 4, 19, 3, 19, 0, time.UTC)
+	layout, err := newTextLayout(&NewConsoleLoggerArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		level LogLevel
+		want  string
+	}{
+		{INFO, "2024/07/04 19:03:19 [\x1b[34;1mINFO\x1b[39;49;22m] g: m {\x1b[31;1mk\x1b[39;49;22m=v}"},
+		{INFO + 1, "2024/07/04 19:03:19 [\x1b[34;1mINFO+1\x1b[39;49;22m] g: m {\x1b[31;1mk\x1b[39;49;22m=v}"},
+		{WARN, "2024/07/04 19:03:19 [WARN] g: m {\x1b[31;1mk\x1b[39;49;22m=v}"},
+	} {
+		got := string(layout.append(nil, theme, &Entry{Time: at, Level: tt.level, Group: "g", Message: "m", Fields: Fields{String("k", "v")}}))
+		if got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+
+	if got := string(layout.append(nil, console.MonochromeTheme(), &Entry{Time: at, Level: ERROR, Group: "g", Message: "m"})); got != "2024/07/04 19:03:19 [ERROR] g: m" {
+		t.Errorf("unexpected monochrome output %q", got)
+	}
+}
+
+func TestConsoleLogger_Color(t *testing.T) {
+	var buf bytes.Buffer
+
+	// The buffer is not a terminal, so colors are only written when forced.
+	for _, tt := range []struct {
+		color *bool
+		env   string
+		want  bool
+	}{
+		{nil, "", false},
+		{nil, "1", true},
+		{PtrBool(true), "", true},
+		{PtrBool(false), "1", false},
+	} {
+		buf.Reset()
+		t.Setenv("FORCE_COLOR", tt.env)
+		if tt.env == "" {
+			os.Unsetenv("FORCE_COLOR")
+		}
+
+		logger := NewConsoleLogger(&NewConsoleLoggerArgs{Format: FormatText, Color: tt.color, Output: &buf})
+		logger.Setup()
+		logger.Log(&Entry{Level: INFO, Group: "g", Message: "m", Fields: Fields{String("k", "v")}})
+
+		if got := strings.Contains(buf.String(), "\x1b["); got != tt.want {
+			t.Errorf("colored = %v, want %v for override %v and FORCE_COLOR %q: %q", got, tt.want, tt.color, tt.env, buf.String())
+		}
+	}
+}
+
+func TestConsoleLogger_Output(t *testing.T) {
+	var out, errs bytes.Buffer
+	logger := NewConsoleLogger(&NewConsoleLoggerArgs{Format: FormatJSON, Output: &out, ErrorOutput: &errs})
+	logger.Setup()
+
+	logger.Log(&Entry{Level: INFO, Group: "g", Message: "info"})
+	logger.Log(&Entry{Level: WARN, Group: "g", Message: "warn"})
+	logger.Log(&Entry{Level: ERROR, Group: "g", Message: "error"})
+
+	if lines := strings.Count(out.String(), "\n"); lines != 1 || !strings.Contains(out.String(), `"message":"info"`) {
+		t.Errorf("unexpected output %q", out.String())
+	}
+	if lines := strings.Count(errs.String(), "\n"); lines != 2 || strings.Contains(errs.String(), `"message":"info"`) {
+		t.Errorf("unexpected error output %q", errs.String())
+	}
+
+	// Without an error output, every entry is written to the output.
+	out.Reset()
+	logger = NewConsoleLogger(&NewConsoleLoggerArgs{Format: FormatText, Output: &out})
+	logger.Setup()
+	logger.Log(&Entry{Level: INFO, Group: "g", Message: "info"})
+	logger.Log(&Entry{Level: ERROR, Group: "g", Message: "error"})
+	if lines := strings.Count(out.String(), "\n"); lines != 2 {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
+
+func TestConsoleLogger_GroupLevels(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewConsoleLogger(&NewConsoleLoggerArgs{Format: FormatText, Level: INFO, Output: &buf, Color: PtrBool(false)})
+	if err := logger.Levels.Set("db.*", TRACE); err != nil {
+		t.Fatal(err)
+	}
+	m := NewMultilog(&NewMultilogArgs{Level: TRACE})
+	if err := m.RegisterLogger(LoggerConsole, logger); err != nil {
+		t.Fatal(err)
+	}
+
+	m.Trace("db.query", "select")
+	m.Debug("api", "request")
+	m.Info("api", "response")
+
+	out := buf.String()
+	if !strings.Contains(out, "select") || !strings.Contains(out, "response") || strings.Contains(out, "request") {
+		t.Errorf("unexpected output %q", out)
+	}
 }
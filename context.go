@@ -0,0 +1,176 @@
+package multilog
+
+import "context"
+
+// contextKey is the key under which NewContext stores fields in a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the given fields, which are added to
+// every message logged with the returned context through the *Ctx log functions.
+//
+// Fields already attached to ctx are kept, and the new fields are added after them.
+//
+// Arguments:
+//   - ctx: The parent context.
+//   - fields: The fields to attach.
+//
+// Returns:
+//   - context.Context: The context carrying the fields.
+func NewContext(ctx context.Context, fields ...Field) context.Context {
+	parent := FieldsFromContext(ctx)
+
+	attached := make(Fields, 0, len(parent)+len(fields))
+	attached = append(attached, parent...)
+	attached = append(attached, fields...)
+
+	return context.WithValue(ctx, contextKey{}, attached)
+}
+
+// FieldsFromContext returns the fields attached to ctx with NewContext.
+//
+// Arguments:
+//   - ctx: The context to read the fields from.
+//
+// Returns:
+//   - Fields: The attached fields, which must not be modified, or nil if there are none.
+func FieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(contextKey{}).(Fields)
+	return fields
+}
+
+// AddContextExtractor registers a function that extracts fields from the context
+// passed to the *Ctx log functions. Extractors are called in the order they were added.
+//
+// Arguments:
+//   - extractor: The context extractor to add.
+func (m *Multilog) AddContextExtractor(extractor ContextExtractor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The slice is copied so that log calls holding the previous one are unaffected.
+	extractors := make([]ContextExtractor, 0, len(m.extractors)+1)
+	extractors = append(extractors, m.extractors...)
+	m.extractors = append(extractors, extractor)
+}
+
+// AddContextExtractor registers a function that extracts fields from the context
+// passed to the *Ctx log functions of the default Multilog.
+//
+// Arguments:
+//   - extractor: The context extractor to add.
+func AddContextExtractor(extractor ContextExtractor) {
+	Default().AddContextExtractor(extractor)
+}
+
+// contextFields returns the fields attached to ctx followed by the fields
+// returned by each context extractor. The result is never shared, so the
+// caller may append to it.
+func (m *Multilog) contextFields(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+
+	m.mu.RLock()
+	extractors := m.extractors
+	m.mu.RUnlock()
+
+	var fields Fields
+	fields = append(fields, FieldsFromContext(ctx)...)
+	for _, extract := range extractors {
+		fields = append(fields, extract(ctx)...)
+	}
+
+	return fields
+}
+
+// TraceCtx logs a trace message at the TRACE level with the fields attached to
+// and extracted from ctx.
+func (m *Multilog) TraceCtx(ctx context.Context, group string, message string, fields ...Field) {
+	m.log(ctx, TRACE, group, message, fields)
+}
+
+// DebugCtx logs a debug message at the DEBUG level with the fields attached to
+// and extracted from ctx.
+func (m *Multilog) DebugCtx(ctx context.Context, group string, message string, fields ...Field) {
+	m.log(ctx, DEBUG, group, message, fields)
+}
+
+// InfoCtx logs an info message at the INFO level with the fields attached to
+// and extracted from ctx.
+func (m *Multilog) InfoCtx(ctx context.Context, group string, message string, fields ...Field) {
+	m.log(ctx, INFO, group, message, fields)
+}
+
+// WarnCtx logs a warn message at the WARN level with the fields attached to
+// and extracted from ctx.
+func (m *Multilog) WarnCtx(ctx context.Context, group string, message string, fields ...Field) {
+	m.log(ctx, WARN, group, message, fields)
+}
+
+// ErrorCtx logs an error message at the ERROR level with the fields attached to
+// and extracted from ctx.
+func (m *Multilog) ErrorCtx(ctx context.Context, group string, message string, fields ...Field) {
+	m.log(ctx, ERROR, group, message, fields)
+}
+
+// FatalCtx logs a fatal message at the FATAL level with the fields attached to
+// and extracted from ctx, shuts the loggers down so that the message is
+// written out and then exits the process with status code 1.
+func (m *Multilog) FatalCtx(ctx context.Context, group string, message string, fields ...Field) {
+	m.log(ctx, FATAL, group, message, fields)
+	m.exit()
+}
+
+// LogCtx logs a message at the given level with the fields attached to and
+// extracted from ctx. Unlike FatalCtx, it never exits the process.
+func (m *Multilog) LogCtx(ctx context.Context, level LogLevel, group string, message string, fields ...Field) {
+	m.log(ctx, level, group, message, fields)
+}
+
+// TraceCtx logs a trace message at the TRACE level with the fields attached to
+// and extracted from ctx.
+func (c *ChildLogger) TraceCtx(ctx context.Context, group string, message string, fields ...Field) {
+	c.log(ctx, TRACE, group, message, fields)
+}
+
+// DebugCtx logs a debug message at the DEBUG level with the fields attached to
+// and extracted from ctx.
+func (c *ChildLogger) DebugCtx(ctx context.Context, group string, message string, fields ...Field) {
+	c.log(ctx, DEBUG, group, message, fields)
+}
+
+// InfoCtx logs an info message at the INFO level with the fields attached to
+// and extracted from ctx.
+func (c *ChildLogger) InfoCtx(ctx context.Context, group string, message string, fields ...Field) {
+	c.log(ctx, INFO, group, message, fields)
+}
+
+// WarnCtx logs a warn message at the WARN level with the fields attached to
+// and extracted from ctx.
+func (c *ChildLogger) WarnCtx(ctx context.Context, group string, message string, fields ...Field) {
+	c.log(ctx, WARN, group, message, fields)
+}
+
+// ErrorCtx logs an error message at the ERROR level with the fields attached to
+// and extracted from ctx.
+func (c *ChildLogger) ErrorCtx(ctx context.Context, group string, message string, fields ...Field) {
+	c.log(ctx, ERROR, group, message, fields)
+}
+
+// FatalCtx logs a fatal message at the FATAL level with the fields attached to
+// and extracted from ctx, shuts the loggers down so that the message is
+// written out and then exits the process with status code 1.
+func (c *ChildLogger) FatalCtx(ctx context.Context, group string, message string, fields ...Field) {
+	c.log(ctx, FATAL, group, message, fields)
+	c.multilog().exit()
+}
+
+// LogCtx logs a message at the given level with the fields attached to and
+// extracted from ctx. Unlike FatalCtx, it never exits the process.
+func (c *ChildLogger) LogCtx(ctx context.Context, level LogLevel, group string, message string, fields ...Field) {
+	c.log(ctx, level, group, message, fields)
+}
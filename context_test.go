@@ -0,0 +1,56 @@
+package multilog
+
+import (
+	"context"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestMultilog_Context(t *testing.T) {
+	var got *Entry
+
+	m := NewMultilog(&NewMultilogArgs{
+		ContextExtractors: []ContextExtractor{
+			func(ctx context.Context) []Field {
+				if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+					return []Field{String("tenant", tenant)}
+				}
+				return nil
+			},
+		},
+	})
+	if err := m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			got = entry
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := NewContext(context.Background(), String("request_id", "abc"))
+	ctx = NewContext(ctx, String("user", "bob"))
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+
+	m.InfoCtx(ctx, "api", "request", Int("status", 200))
+	if want := "{request_id=abc user=bob tenant=acme status=200}"; got.Fields.Text() != want {
+		t.Errorf("expected fields %s, got %s", want, got.Fields.Text())
+	}
+
+	m.AddContextExtractor(func(ctx context.Context) []Field {
+		return []Field{Bool("extracted", true)}
+	})
+	m.WithGroup("api").With(String("bound", "yes")).WarnCtx(ctx, "auth", "denied")
+	if want := "{request_id=abc user=bob tenant=acme extracted=true bound=yes}"; got.Fields.Text() != want {
+		t.Errorf("expected fields %s, got %s", want, got.Fields.Text())
+	}
+	if got.Group != "api.auth" {
+		t.Errorf("expected group api.auth, got %s", got.Group)
+	}
+
+	// The parent context must not see the fields attached to its children.
+	if fields := FieldsFromContext(context.Background()); fields != nil {
+		t.Errorf("expected no fields, got %s", fields.Text())
+	}
+}
@@ -0,0 +1,203 @@
+package multilog
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Encoder encodes entries to bytes for the sinks that write them out, such as
+// files, sockets and the console.
+type Encoder interface {
+	// Encode appends the encoded entry to dst and returns the extended buffer.
+	Encode(dst []byte, entry *Entry) ([]byte, error)
+}
+
+// EncoderFunc is an adapter to allow the use of ordinary functions as encoders.
+type EncoderFunc func(dst []byte, entry *Entry) ([]byte, error)
+
+// Encode calls f(dst, entry).
+func (f EncoderFunc) Encode(dst []byte, entry *Entry) ([]byte, error) {
+	return f(dst, entry)
+}
+
+// NewEncoder returns the built-in encoder for a format.
+//
+// Arguments:
+//   - format: The format to encode entries in.
+//
+// Returns:
+//   - Encoder: A JSONEncoder for FormatJSON or a TextEncoder for FormatText.
+//   - error: If the format is not supported.
+func NewEncoder(format Format) (Encoder, error) {
+	switch format {
+	case FormatJSON:
+		return JSONEncoder{}, nil
+	case FormatText:
+		return TextEncoder{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// JSONEncoder encodes entries as one JSON object per line, holding the time,
+// level, group, message and data, the fields of the entry as an object.
+//
+// It appends to the buffer without reflection for every field type but
+// AnyType, so encoding into a reused buffer does not allocate.
+type JSONEncoder struct {
+	// Timestamp adds an @timestamp field holding the time ahead of the other
+	// fields, as Elasticsearch data streams and many log shippers expect.
+	Timestamp bool
+}
+
+// Encode implements Encoder.
+func (e JSONEncoder) Encode(dst []byte, entry *Entry) ([]byte, error) {
+	start := len(dst)
+
+	dst = append(dst, '{')
+	if e.Timestamp {
+		dst = append(dst, `"@timestamp":`...)
+		dst = appendJSONTime(dst, entry.Time)
+		dst = append(dst, ',')
+	}
+	dst = append(dst, `"time":`...)
+	dst = appendJSONTime(dst, entry.Time)
+	dst = append(dst, `,"level":`...)
+	dst = appendJSONString(dst, entry.Level.String())
+	dst = append(dst, `,"group":`...)
+	dst = appendJSONString(dst, entry.Group)
+	dst = append(dst, `,"message":`...)
+	dst = appendJSONString(dst, entry.Message)
+	dst = append(dst, `,"data":`...)
+
+	dst, err := entry.Fields.AppendJSON(dst)
+	if err != nil {
+		return dst[:start], fmt.Errorf("error marshalling entry: %w", err)
+	}
+
+	return append(dst, '}', '\n'), nil
+}
+
+// DefaultTextTimeLayout is the time layout used when TextEncoder.TimeLayout is not set.
+const DefaultTextTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// TextEncoder encodes entries as one human-readable line, such as:
+//
+//	2024-07-04T19:03:19.000Z [WARN] api: request failed {status=500}
+type TextEncoder struct {
+	// TimeLayout is the layout of the time. Defaults to DefaultTextTimeLayout.
+	TimeLayout string
+}
+
+// Encode implements Encoder.
+func (e TextEncoder) Encode(dst []byte, entry *Entry) ([]byte, error) {
+	layout := e.TimeLayout
+	if layout == "" {
+		layout = DefaultTextTimeLayout
+	}
+
+	dst = entry.Time.AppendFormat(dst, layout)
+	dst = append(dst, " ["...)
+	dst = append(dst, entry.Level.String()...)
+	dst = append(dst, "] "...)
+	dst = append(dst, entry.Group...)
+	dst = append(dst, ": "...)
+	dst = append(dst, entry.Message...)
+	if len(entry.Fields) > 0 {
+		dst = append(dst, ' ')
+		dst = append(dst, entry.Fields.Text()...)
+	}
+
+	return append(dst, '\n'), nil
+}
+
+// LogfmtEncoder encodes entries as one logfmt line, with the fields of nested
+// objects flattened into dotted keys, such as:
+//
+//	time=2024-07-04T19:03:19Z level=warn group=api msg="request failed" status=500 user.id=bob
+type LogfmtEncoder struct{}
+
+// Encode implements Encoder.
+func (LogfmtEncoder) Encode(dst []byte, entry *Entry) ([]byte, error) {
+	dst = append(dst, "time="...)
+	dst = entry.Time.AppendFormat(dst, time.RFC3339Nano)
+	dst = append(dst, " level="...)
+	dst = append(dst, strings.ToLower(entry.Level.String())...)
+	dst = append(dst, " group="...)
+	dst = appendLogfmtValue(dst, entry.Group)
+	dst = append(dst, " msg="...)
+	dst = appendLogfmtValue(dst, entry.Message)
+	dst = appendLogfmtFields(dst, "", entry.Fields)
+
+	return append(dst, '\n'), nil
+}
+
+// appendLogfmtFields appends the fields as key=value pairs, flattening nested
+// objects into dotted keys.
+func appendLogfmtFields(dst []byte, prefix string, fields Fields) []byte {
+	for _, f := range fields {
+		key := f.Key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if f.Type == ObjectType {
+			dst = appendLogfmtFields(dst, key, f.Interface.(Fields))
+			continue
+		}
+
+		dst = append(dst, ' ')
+		dst = appendLogfmtKey(dst, key)
+		dst = append(dst, '=')
+		switch f.Type {
+		case IntType:
+			dst = strconv.AppendInt(dst, f.Integer, 10)
+		case UintType:
+			dst = strconv.AppendUint(dst, uint64(f.Integer), 10)
+		case BoolType:
+			dst = strconv.AppendBool(dst, f.Integer == 1)
+		case TimeType:
+			dst = f.time().AppendFormat(dst, time.RFC3339Nano)
+		default:
+			dst = appendLogfmtValue(dst, f.Text())
+		}
+	}
+
+	return dst
+}
+
+// appendLogfmtKey appends a key, replacing the characters logfmt does not
+// allow in keys with underscores.
+func appendLogfmtKey(dst []byte, key string) []byte {
+	if key == "" {
+		return append(dst, '_')
+	}
+
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			r = '_'
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+
+	return dst
+}
+
+// appendLogfmtValue appends a value, quoting it when it is empty or holds
+// spaces, equal signs, quotes or control characters.
+func appendLogfmtValue(dst []byte, value string) []byte {
+	if value == "" {
+		return append(dst, `""`...)
+	}
+
+	if strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError
+	}) < 0 {
+		return append(dst, value...)
+	}
+
+	return strconv.AppendQuote(dst, value)
+}
@@ -0,0 +1,210 @@
+package multilog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func testEntry() *Entry {
+	return &Entry{
+		Time:    time.Date(2024, 7, 4, 19, 3, 19, 0, time.UTC),
+		Level:   WARN,
+		Group:   "api",
+		Message: "request failed",
+		Fields: Fields{
+			Int("status", 500),
+			String("path", "/users/1"),
+			Err(errors.New("bad gateway")),
+			Object("user", String("id", "bob"), Bool("admin", false)),
+		},
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoder Encoder
+		want    string
+	}{
+		{
+			name:    "json",
+			encoder: JSONEncoder{},
+			want:    `{"time":"2024-07-04T19:03:19Z","level":"WARN","group":"api","message":"request failed","data":{"status":500,"path":"/users/1","error":"bad gateway","user":{"id":"bob","admin":false}}}` + "\n",
+		},
+		{
+			name:    "text",
+			encoder: TextEncoder{},
+			want:    "2024-07-04T19:03:19.000Z [WARN] api: request failed {status=500 path=/users/1 error=bad gateway user={id=bob admin=false}}\n",
+		},
+		{
+			name:    "logfmt",
+			encoder: LogfmtEncoder{},
+			want:    `time=2024-07-04T19:03:19Z level=warn group=api msg="request failed" status=500 path=/users/1 error="bad gateway" user.id=bob user.admin=false` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.encoder.Encode([]byte("prefix:"), testEntry())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != "prefix:"+tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessagePackEncoder(t *testing.T) {
+	entry := &Entry{
+		Time:    time.Unix(1, 2),
+		Level:   ERROR,
+		Group:   "g",
+		Message: "m",
+		Fields:  Fields{Int("n", -1), Float64("f", 1.5), Bool("b", true), Any("nil", nil)},
+	}
+
+	got, err := MessagePackEncoder{}.Encode(nil, entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "85" + // map of 5 entries
+		"a474696d65" + "c70cff" + "00000002" + "0000000000000001" + // time: timestamp 96
+		"a56c6576656c" + "a54552524f52" + // level: "ERROR"
+		"a567726f7570" + "a167" + // group: "g"
+		"a76d657373616765" + "a16d" + // message: "m"
+		"a464617461" + "84" + // data: map of 4 entries
+		"a16e" + "ff" + // n: -1
+		"a166" + "cb3ff8000000000000" + // f: 1.5
+		"a162" + "c3" + // b: true
+		"a36e696c" + "c0" // nil: nil
+	if hex.EncodeToString(got) != want {
+		t.Errorf("got %x, want %s", got, want)
+	}
+}
+
+func TestWriterLogger(t *testing.T) {
+	b := bytes.Buffer{}
+	logger := NewWriterLogger(&NewWriterLoggerArgs{Writer: &b, Encoder: LogfmtEncoder{}, Level: INFO})
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Level argument is applied by the dispatcher through the logger's Levels.
+	if logger.Levels.Enabled(DEBUG, "api") || !logger.Levels.Enabled(INFO, "api") {
+		t.Errorf("unexpected levels with base %s", logger.Levels.Base())
+	}
+	logger.Log(&Entry{Time: time.Unix(0, 0).UTC(), Level: INFO, Group: "api", Message: "hello", Fields: Fields{Int("n", 1)}})
+	logger.Log(&Entry{Time: time.Unix(0, 0).UTC(), Level: INFO, Group: "api", Message: "world"})
+
+	want := "time=1970-01-01T00:00:00Z level=info group=api msg=hello n=1\n" +
+		"time=1970-01-01T00:00:00Z level=info group=api msg=world\n"
+	if b.String() != want {
+		t.Errorf("got %q, want %q", b.String(), want)
+	}
+
+	if err := NewWriterLogger(&NewWriterLoggerArgs{}).Setup(); err == nil {
+		t.Error("expected an error setting up without a writer")
+	}
+}
+
+func TestAppendJSON_MatchesEncodingJSON(t *testing.T) {
+	values := []string{"", "plain", `quote " and \ backslash`, "tab\tnewline\nreturn\r", "\x00\x1f\x7f", "é ü 日本", "\xff invalid", "\u2028\u2029"}
+	for _, s := range values {
+		want, _ := json.Marshal(s)
+		if got := appendJSONString(nil, s); string(got) != string(want) {
+			t.Errorf("appendJSONString(%q) = %s, want %s", s, got, want)
+		}
+	}
+
+	floats := []float64{0, 1, -1.5, 1e-7, 1e21, 123456789.125, 1e20, 5e-324}
+	for _, f := range floats {
+		want, _ := json.Marshal(f)
+		if got := appendJSONFloat(nil, f); string(got) != string(want) {
+			t.Errorf("appendJSONFloat(%v) = %s, want %s", f, got, want)
+		}
+	}
+}
+
+func TestJSONEncoder_Allocations(t *testing.T) {
+	entry := benchmarkEntry()
+	buf := make([]byte, 0, 1024)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = JSONEncoder{}.Encode(buf[:0], entry)
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations, got %v", allocs)
+	}
+}
+
+// benchmarkEntry returns an entry with the common field types.
+func benchmarkEntry() *Entry {
+	return &Entry{
+		Time:    time.Date(2024, 7, 4, 19, 3, 19, 123456789, time.UTC),
+		Level:   INFO,
+		Group:   "http",
+		Message: "request handled",
+		Fields: Fields{
+			String("method", "GET"),
+			String("path", "/api/v1/users/42"),
+			Int("status", 200),
+			Float64("ratio", 0.75),
+			Bool("cached", true),
+			Time("started", time.Date(2024, 7, 4, 19, 3, 19, 0, time.UTC)),
+			Object("user", String("id", "42"), String("name", "bob")),
+		},
+	}
+}
+
+func BenchmarkJSONEncoder(b *testing.B) {
+	entry := benchmarkEntry()
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf := getBuffer()
+			*buf, _ = JSONEncoder{}.Encode(*buf, entry)
+			putBuffer(buf)
+		}
+	})
+}
+
+// BenchmarkJSONMarshalMap measures the path the JSON console format used to
+// take, marshalling the fields as a map with encoding/json.
+func BenchmarkJSONMarshalMap(b *testing.B) {
+	entry := benchmarkEntry()
+	b.ReportAllocs()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			json.Marshal(map[string]interface{}{
+				"time":    entry.Time,
+				"level":   entry.Level,
+				"group":   entry.Group,
+				"message": entry.Message,
+				"data":    entry.Fields.Map(),
+			})
+		}
+	})
+}
+
+// BenchmarkPrettyHandler measures PrettyHandler.Handle, which the JSON console
+// format used before it switched to the JSONEncoder.
+func BenchmarkPrettyHandler(b *testing.B) {
+	entry := benchmarkEntry()
+	logger := slog.New(NewPrettyHandler(io.Discard, PrettyHandlerOptions{}))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		logger.With(slog.String("group", entry.Group)).Info(entry.Message, "data", entry.Fields.Map())
+	}
+}
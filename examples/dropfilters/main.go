@@ -4,11 +4,6 @@ import (
 	"github.com/mateothegreat/multilog"
 )
 
-type CustomLogData struct {
-	Foo string `json:"foo"`
-	Bar int    `json:"bar"`
-}
-
 func init() {
 	multilog.RegisterLogger(multilog.LogMethod("console"), multilog.NewConsoleLogger(&multilog.NewConsoleLoggerArgs{
 		Format: multilog.FormatText,
@@ -20,7 +15,7 @@ func init() {
 }
 
 func main() {
-	multilog.Trace("nobody_cares_about_this", "this message will get dropped by the filters", nil)
-	multilog.Error("block_this_group", "this message will get dropped by the filters", nil)
-	multilog.Fatal("die", "this will crash", nil)
+	multilog.Trace("nobody_cares_about_this", "this message will get dropped by the filters")
+	multilog.Error("block_this_group", "this message will get dropped by the filters")
+	multilog.Fatal("die", "this will crash")
 }
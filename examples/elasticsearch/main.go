@@ -5,11 +5,6 @@ import (
 	elasticsearch "github.com/mateothegreat/multilog/logger/elasticsearch"
 )
 
-type CustomLogData struct {
-	Foo string `json:"foo"`
-	Bar int    `json:"bar"`
-}
-
 func init() {
 	multilog.RegisterLogger(multilog.LogMethod("console"), multilog.NewConsoleLogger(&multilog.NewConsoleLoggerArgs{
 		Format: multilog.FormatText,
@@ -43,7 +38,7 @@ func init() {
 }
 
 func main() {
-	multilog.Trace("nobody_cares_about_this", "this message will get dropped by the filters", nil)
-	multilog.Error("block_this_group", "this message will get dropped by the filters", nil)
-	multilog.Fatal("die", "this will crash", nil)
+	multilog.Trace("nobody_cares_about_this", "this message will get dropped by the filters")
+	multilog.Error("block_this_group", "this message will get dropped by the filters")
+	multilog.Fatal("die", "this will crash")
 }
@@ -6,11 +6,6 @@ import (
 	"github.com/mateothegreat/multilog"
 )
 
-type CustomLogData struct {
-	Foo string `json:"foo"`
-	Bar int    `json:"bar"`
-}
-
 func init() {
 	multilog.RegisterLogger(multilog.LogMethod("console"), multilog.NewConsoleLogger(&multilog.NewConsoleLoggerArgs{
 		Level:  multilog.TRACE,
@@ -54,8 +49,9 @@ func init() {
 	// }))
 
 	multilog.RegisterLogger(multilog.LogMethod("customerLogger1"), &multilog.CustomLogger{
-		Log: func(level multilog.LogLevel, group string, message string, v map[string]interface{}) {
-			log.Printf("logged via customerLogger1: %s: %s", group, message)
+		Log: func(entry *multilog.Entry) error {
+			log.Printf("logged via customerLogger1: %s: %s", entry.Group, entry.Message)
+			return nil
 		},
 	})
 
@@ -63,40 +59,42 @@ func init() {
 	customLogger1 := multilog.NewLogger(multilog.LogMethod("customerLogger2"))
 	// If needed, you can do stuff here when the logger is setup such as
 	// connecting to something like elasticsearch or whatever:
-	customLogger1.Setup = func() {
+	customLogger1.Setup = func() error {
 		log.Println("Setup customerLogger2")
+		return nil
 	}
 	// Define the log method:
-	customLogger1.Log = func(level multilog.LogLevel, group string, message string, v map[string]interface{}) {
-		log.Printf("logged via customerLogger: %s: %s", group, message)
+	customLogger1.Log = func(entry *multilog.Entry) error {
+		log.Printf("logged via customerLogger: %s: %s", entry.Group, entry.Message)
+		return nil
 	}
 }
 
 func main() {
-	multilog.Debug("my_package_name", "test", map[string]interface{}{
-		"foo": "foo",
-		"bar": 1,
-	})
-	multilog.Warn("my_package_name", "it's about to explode...", map[string]interface{}{
-		"foo": "boom",
-		"bar": 1234234234234,
-	})
+	multilog.Debug("my_package_name", "test",
+		multilog.String("foo", "foo"),
+		multilog.Int("bar", 1),
+	)
+	multilog.Warn("my_package_name", "it's about to explode...",
+		multilog.String("foo", "boom"),
+		multilog.Int("bar", 1234234234234),
+	)
 
-	multilog.Error("my_package_name", "some error!", map[string]interface{}{
-		"foo": "bad things happened bro",
-		"bar": 123,
-	})
+	multilog.Error("my_package_name", "some error!",
+		multilog.String("foo", "bad things happened bro"),
+		multilog.Int("bar", 123),
+	)
 
-	multilog.Info("my_package_name", "some verbose info..", map[string]interface{}{
-		"foo": "it's happpeeennning!!!",
-		"bar": 234234234,
-	})
+	multilog.Info("my_package_name", "some verbose info..",
+		multilog.String("foo", "it's happpeeennning!!!"),
+		multilog.Int("bar", 234234234),
+	)
 
-	multilog.Fatal("my_package_name", "this will crash", map[string]interface{}{
-		"foo": "boom",
-		"bar": 1234234234234,
-	})
+	multilog.Fatal("my_package_name", "this will crash",
+		multilog.String("foo", "boom"),
+		multilog.Int("bar", 1234234234234),
+	)
 
-	multilog.Trace("nobody_cares_about_this", "this message will get dropped by the filters", nil)
-	multilog.Error("block_this_group", "this message will get dropped by the filters", nil)
+	multilog.Trace("nobody_cares_about_this", "this message will get dropped by the filters")
+	multilog.Error("block_this_group", "this message will get dropped by the filters")
 }
@@ -0,0 +1,266 @@
+package multilog
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// FieldType is the type of the value held by a Field.
+type FieldType uint8
+
+const (
+	// AnyType is a field holding an arbitrary value in Field.Interface.
+	AnyType FieldType = iota
+	// StringType is a field holding a string in Field.String.
+	StringType
+	// IntType is a field holding an int64 in Field.Integer.
+	IntType
+	// UintType is a field holding a uint64 in Field.Integer.
+	UintType
+	// FloatType is a field holding the bits of a float64 in Field.Integer.
+	FloatType
+	// BoolType is a field holding a bool in Field.Integer as 0 or 1.
+	BoolType
+	// DurationType is a field holding a time.Duration in Field.Integer.
+	DurationType
+	// TimeType is a field holding a time.Time as Unix nanoseconds in Field.Integer
+	// and its *time.Location in Field.Interface.
+	TimeType
+	// ErrorType is a field holding an error in Field.Interface.
+	ErrorType
+	// ObjectType is a field holding nested Fields in Field.Interface.
+	ObjectType
+)
+
+// Field is a typed key/value pair logged with a message.
+//
+// Fields are created with the typed constructors such as String, Int and Err,
+// which store common types without allocating.
+type Field struct {
+	Key       string    // Key is the name of the field.
+	Type      FieldType // Type is the type of the value.
+	Integer   int64     // Integer holds the value of integer, float, bool, duration and time fields.
+	String    string    // String holds the value of string fields.
+	Interface any       // Interface holds the value of any, error, time and object fields.
+}
+
+// Fields is an ordered list of fields.
+type Fields []Field
+
+// String creates a field holding a string.
+func String(key string, value string) Field {
+	return Field{Key: key, Type: StringType, String: value}
+}
+
+// Int creates a field holding an int.
+func Int(key string, value int) Field {
+	return Int64(key, int64(value))
+}
+
+// Int64 creates a field holding an int64.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Type: IntType, Integer: value}
+}
+
+// Uint64 creates a field holding a uint64.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Type: UintType, Integer: int64(value)}
+}
+
+// Float64 creates a field holding a float64.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Type: FloatType, Integer: int64(math.Float64bits(value))}
+}
+
+// Bool creates a field holding a bool.
+func Bool(key string, value bool) Field {
+	var i int64
+	if value {
+		i = 1
+	}
+
+	return Field{Key: key, Type: BoolType, Integer: i}
+}
+
+// Duration creates a field holding a time.Duration.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Type: DurationType, Integer: int64(value)}
+}
+
+// Time creates a field holding a time.Time.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Type: TimeType, Integer: value.UnixNano(), Interface: value.Location()}
+}
+
+// Err creates a field named "error" holding an error.
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr creates a field holding an error.
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Type: AnyType}
+	}
+
+	return Field{Key: key, Type: ErrorType, Interface: err}
+}
+
+// Object creates a field holding nested fields.
+func Object(key string, fields ...Field) Field {
+	return Field{Key: key, Type: ObjectType, Interface: Fields(fields)}
+}
+
+// Any creates a field holding an arbitrary value, using the typed
+// representation when the value is of a supported type.
+func Any(key string, value any) Field {
+	switch v := value.(type) {
+	case string:
+		return String(key, v)
+	case int:
+		return Int(key, v)
+	case int8:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint8:
+		return Uint64(key, uint64(v))
+	case uint16:
+		return Uint64(key, uint64(v))
+	case uint32:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	case []Field:
+		return Object(key, v...)
+	case Fields:
+		return Object(key, v...)
+	default:
+		return Field{Key: key, Type: AnyType, Interface: value}
+	}
+}
+
+// FieldsFromMap converts a map to fields sorted by key.
+//
+// It eases the migration of call sites that build a map[string]interface{}.
+func FieldsFromMap(m map[string]interface{}) Fields {
+	fields := make(Fields, 0, len(m))
+	for key, value := range m {
+		fields = append(fields, Any(key, value))
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+
+	return fields
+}
+
+// Value returns the value of the field as its natural Go type. Object fields
+// return their nested Fields.
+func (f Field) Value() any {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case IntType:
+		return f.Integer
+	case UintType:
+		return uint64(f.Integer)
+	case FloatType:
+		return math.Float64frombits(uint64(f.Integer))
+	case BoolType:
+		return f.Integer == 1
+	case DurationType:
+		return time.Duration(f.Integer)
+	case TimeType:
+		return f.time()
+	default:
+		return f.Interface
+	}
+}
+
+// time returns the value of a TimeType field.
+func (f Field) time() time.Time {
+	t := time.Unix(0, f.Integer)
+	if loc, ok := f.Interface.(*time.Location); ok {
+		t = t.In(loc)
+	}
+
+	return t
+}
+
+// Text returns the value of the field formatted for human-readable output.
+func (f Field) Text() string {
+	switch f.Type {
+	case StringType:
+		return f.String
+	case DurationType:
+		return time.Duration(f.Integer).String()
+	case TimeType:
+		return f.time().Format(time.RFC3339Nano)
+	case ErrorType:
+		return f.Interface.(error).Error()
+	case ObjectType:
+		return f.Interface.(Fields).Text()
+	default:
+		return fmt.Sprint(f.Value())
+	}
+}
+
+// Text returns the fields formatted as {key=value ...} for human-readable output.
+func (fs Fields) Text() string {
+	b := bytes.Buffer{}
+	b.WriteByte('{')
+	for i, f := range fs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(f.Text())
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// Map returns the fields as a map, with nested objects converted to maps.
+func (fs Fields) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(fs))
+	for _, f := range fs {
+		switch f.Type {
+		case ObjectType:
+			m[f.Key] = f.Interface.(Fields).Map()
+		case ErrorType:
+			m[f.Key] = f.Interface.(error).Error()
+		default:
+			m[f.Key] = f.Value()
+		}
+	}
+
+	return m
+}
+
+// MarshalJSON implements json.Marshaler, encoding the fields as a JSON object
+// with the keys in order.
+func (fs Fields) MarshalJSON() ([]byte, error) {
+	return fs.AppendJSON(nil)
+}
@@ -0,0 +1,59 @@
+package multilog
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFields_MarshalJSON(t *testing.T) {
+	fields := Fields{
+		String("b", "text"),
+		Int("a", 1),
+		Float64("c", 1.5),
+		Bool("d", true),
+		Duration("e", 1500*time.Millisecond),
+		Time("f", time.Date(2024, 7, 4, 19, 3, 19, 0, time.UTC)),
+		Err(errors.New("boom")),
+		Object("g", Uint64("x", 2), Any("y", []int{1, 2})),
+		Err(nil),
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"b":"text","a":1,"c":1.5,"d":true,"e":"1.5s","f":"2024-07-04T19:03:19Z","error":"boom","g":{"x":2,"y":[1,2]},"error":null}`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
+
+func TestFields_Any(t *testing.T) {
+	for _, tc := range []struct {
+		value any
+		want  FieldType
+	}{
+		{"text", StringType},
+		{int32(1), IntType},
+		{uint8(1), UintType},
+		{float32(1), FloatType},
+		{false, BoolType},
+		{time.Second, DurationType},
+		{time.Now(), TimeType},
+		{errors.New("boom"), ErrorType},
+		{Fields{}, ObjectType},
+		{struct{}{}, AnyType},
+	} {
+		if got := Any("key", tc.value); got.Type != tc.want {
+			t.Errorf("Any(%T): got type %d, want %d", tc.value, got.Type, tc.want)
+		}
+	}
+
+	fields := FieldsFromMap(map[string]interface{}{"b": 2, "a": "1"})
+	if fields.Text() != "{a=1 b=2}" {
+		t.Errorf("unexpected fields from map: %s", fields.Text())
+	}
+}
@@ -3,8 +3,9 @@ module github.com/mateothegreat/multilog
 go 1.25.3
 
 require (
-	github.com/fatih/color v1.17.0
 	github.com/mateothegreat/multilog/logger/elasticsearch v0.0.0-20251023221020-f38f7d591b17
+	go.opentelemetry.io/otel v1.28.0
+	go.opentelemetry.io/otel/trace v1.28.0
 )
 
 require (
@@ -12,10 +13,7 @@ require (
 	github.com/elastic/go-elasticsearch/v8 v8.19.0 // indirect
 	github.com/go-logr/logr v1.4.2 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
-	github.com/mattn/go-colorable v0.1.13 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	go.opentelemetry.io/otel v1.28.0 // indirect
 	go.opentelemetry.io/otel/metric v1.28.0 // indirect
-	go.opentelemetry.io/otel/trace v1.28.0 // indirect
-	golang.org/x/sys v0.24.0 // indirect
 )
+
+replace github.com/mateothegreat/multilog/logger/elasticsearch => ./logger/elasticsearch
@@ -0,0 +1,195 @@
+package multilog
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// bufferPool holds the buffers entries are encoded into by the sinks, so that
+// encoding does not allocate once the pool is warm.
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// maxPooledBuffer is the capacity above which buffers are not returned to the
+// pool, so that a single large entry does not pin memory.
+const maxPooledBuffer = 64 << 10
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+// putBuffer returns a buffer to the pool.
+func putBuffer(b *[]byte) {
+	if cap(*b) <= maxPooledBuffer {
+		bufferPool.Put(b)
+	}
+}
+
+// AppendJSON appends the fields to dst as a JSON object with the keys in order,
+// without reflection for every field type but AnyType.
+//
+// Arguments:
+//   - dst: The buffer to append to.
+//
+// Returns:
+//   - []byte: The extended buffer.
+//   - error: If a value held by an AnyType field cannot be marshalled.
+func (fs Fields) AppendJSON(dst []byte) ([]byte, error) {
+	dst = append(dst, '{')
+	for i, f := range fs {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, f.Key)
+		dst = append(dst, ':')
+
+		var err error
+		if dst, err = f.appendJSON(dst); err != nil {
+			return dst, fmt.Errorf("error marshalling field %s: %w", f.Key, err)
+		}
+	}
+
+	return append(dst, '}'), nil
+}
+
+// appendJSON appends the value of the field to dst as JSON.
+func (f Field) appendJSON(dst []byte) ([]byte, error) {
+	switch f.Type {
+	case StringType:
+		return appendJSONString(dst, f.String), nil
+	case IntType:
+		return strconv.AppendInt(dst, f.Integer, 10), nil
+	case UintType:
+		return strconv.AppendUint(dst, uint64(f.Integer), 10), nil
+	case FloatType:
+		return appendJSONFloat(dst, math.Float64frombits(uint64(f.Integer))), nil
+	case BoolType:
+		return strconv.AppendBool(dst, f.Integer == 1), nil
+	case DurationType:
+		return appendJSONString(dst, time.Duration(f.Integer).String()), nil
+	case TimeType:
+		return appendJSONTime(dst, f.time()), nil
+	case ErrorType:
+		return appendJSONString(dst, f.Interface.(error).Error()), nil
+	case ObjectType:
+		return f.Interface.(Fields).AppendJSON(dst)
+	}
+
+	if f.Interface == nil {
+		return append(dst, "null"...), nil
+	}
+	data, err := json.Marshal(f.Interface)
+	if err != nil {
+		return dst, err
+	}
+
+	return append(dst, data...), nil
+}
+
+// appendJSONTime appends a time as a quoted RFC 3339 string, as encoding/json does.
+func appendJSONTime(dst []byte, t time.Time) []byte {
+	dst = append(dst, '"')
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	return append(dst, '"')
+}
+
+// appendJSONFloat appends a float as encoding/json does. NaN and infinities,
+// which JSON cannot represent, are appended as strings.
+func appendJSONFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONString(dst, strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+
+	if format == 'e' {
+		// Clean up e-09 to e-9, as encoding/json does.
+		n := len(dst)
+		if n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+
+	return dst
+}
+
+// jsonSafe reports the ASCII characters that can be appended to a JSON string as is.
+var jsonSafe = func() (safe [utf8.RuneSelf]bool) {
+	for c := ' '; c < utf8.RuneSelf; c++ {
+		safe[c] = c != '"' && c != '\\'
+	}
+	return safe
+}()
+
+// appendJSONString appends a string as a quoted JSON string, replacing invalid
+// UTF-8 with the replacement character as encoding/json does. Unlike
+// encoding/json, <, > and & are not escaped since the output is not HTML.
+func appendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if jsonSafe[c] {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// U+2028 and U+2029 are valid JSON but break JavaScript, so they are
+		// escaped as encoding/json does.
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+
+	return append(dst, '"')
+}
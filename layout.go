@@ -0,0 +1,290 @@
+package multilog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mateothegreat/multilog/console"
+)
+
+// DefaultConsoleLayout is the layout of the text lines of the console logger
+// when NewConsoleLoggerArgs.Layout is not set.
+const DefaultConsoleLayout = "{time} [{level}] {group}: {message} {fields}"
+
+// DefaultConsoleTimeLayout is the Go time layout of the {time} placeholder when
+// NewConsoleLoggerArgs.TimeLayout is not set.
+const DefaultConsoleTimeLayout = "2006/01/02 15:04:05"
+
+// DefaultGroupWidth is the width the {group} placeholder is padded to when
+// NewConsoleLoggerArgs.Align is set and GroupWidth is not.
+const DefaultGroupWidth = 16
+
+// levelWidth is the width the {level} placeholder is padded to when aligned,
+// which is the length of the longest built-in level name.
+const levelWidth = 5
+
+// FieldFormat is how the {fields} placeholder renders the fields.
+type FieldFormat string
+
+const (
+	// FieldFormatKeyValue renders the fields on the line as {key=value ...}.
+	FieldFormatKeyValue FieldFormat = "key_value"
+	// FieldFormatJSON renders the fields as an indented JSON object on the
+	// lines following the line, and nothing when there are none.
+	FieldFormatJSON FieldFormat = "json"
+)
+
+// CallerPath is how the console renders the path of the caller.
+type CallerPath string
+
+const (
+	// CallerPathShort renders the last directory and the file name of the
+	// caller, such as api/handler.go:42.
+	CallerPathShort CallerPath = "short"
+	// CallerPathLong renders the full path of the caller.
+	CallerPathLong CallerPath = "long"
+)
+
+// placeholders are the placeholders of a layout.
+var placeholders = map[string]bool{
+	"time":    true,
+	"level":   true,
+	"group":   true,
+	"caller":  true,
+	"message": true,
+	"fields":  true,
+}
+
+// textLayout is a parsed layout of the text lines.
+type textLayout struct {
+	segments    []layoutSegment // segments are the literal text and placeholders, in order.
+	timeLayout  string          // timeLayout is the Go time layout of the {time} placeholder.
+	utc         bool            // utc renders the time in UTC rather than in its own location.
+	align       bool            // align pads the level and group to fixed widths.
+	groupWidth  int             // groupWidth is the width the group is padded to.
+	fieldFormat FieldFormat     // fieldFormat is how the fields are rendered.
+	caller      bool            // caller reports whether the layout has the {caller} placeholder.
+	callerPath  CallerPath      // callerPath is how the path of the caller is rendered.
+}
+
+// layoutSegment is either literal text or a placeholder.
+type layoutSegment struct {
+	literal     string // literal is the text of a literal segment.
+	placeholder string // placeholder is the name of a placeholder, empty for literal text.
+}
+
+// newTextLayout parses the layout of the console logger's arguments.
+func newTextLayout(args *NewConsoleLoggerArgs) (*textLayout, error) {
+	layout := &textLayout{
+		timeLayout:  args.TimeLayout,
+		utc:         args.UTC,
+		align:       args.Align,
+		groupWidth:  args.GroupWidth,
+		fieldFormat: args.FieldFormat,
+		callerPath:  args.CallerPath,
+	}
+	if layout.timeLayout == "" {
+		layout.timeLayout = DefaultConsoleTimeLayout
+	}
+	if layout.groupWidth <= 0 {
+		layout.groupWidth = DefaultGroupWidth
+	}
+	switch layout.fieldFormat {
+	case "":
+		layout.fieldFormat = FieldFormatKeyValue
+	case FieldFormatKeyValue, FieldFormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown field format %s", layout.fieldFormat)
+	}
+	switch layout.callerPath {
+	case "":
+		layout.callerPath = CallerPathShort
+	case CallerPathShort, CallerPathLong:
+	default:
+		return nil, fmt.Errorf("unknown caller path %s", layout.callerPath)
+	}
+
+	text := args.Layout
+	if text == "" {
+		text = DefaultConsoleLayout
+	}
+	for text != "" {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			layout.segments = append(layout.segments, layoutSegment{literal: text})
+			break
+		}
+		end := strings.IndexByte(text[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder in layout %q", args.Layout)
+		}
+		name := text[start+1 : start+end]
+		if !placeholders[name] {
+			return nil, fmt.Errorf("unknown placeholder {%s} in layout %q", name, args.Layout)
+		}
+
+		if start > 0 {
+			layout.segments = append(layout.segments, layoutSegment{literal: text[:start]})
+		}
+		layout.segments = append(layout.segments, layoutSegment{placeholder: name})
+		layout.caller = layout.caller || name == "caller"
+		text = text[start+end+1:]
+	}
+
+	return layout, nil
+}
+
+// append appends an entry as a line colored by the theme, followed by the
+// fields in the JSON format and by the stack trace, without the trailing newline.
+func (l *textLayout) append(dst []byte, theme *console.Theme, entry *Entry) []byte {
+	start := len(dst)
+	rendered, caller, stack := l.split(entry.Fields)
+	var below []byte
+
+	// The padding of an aligned placeholder goes after the literal that follows
+	// it, so that a layout such as "[{level}] " renders "[INFO]  " rather than
+	// "[INFO ] ".
+	padding := 0
+	for _, segment := range l.segments {
+		if segment.placeholder != "" {
+			dst = appendSpaces(dst, padding)
+			padding = 0
+		}
+
+		switch segment.placeholder {
+		case "":
+			dst = append(dst, segment.literal...)
+			dst = appendSpaces(dst, padding)
+			padding = 0
+		case "time":
+			t := entry.Time
+			if l.utc {
+				t = t.UTC()
+			}
+			dst = theme.Timestamp.Append(dst, t.Format(l.timeLayout))
+		case "level":
+			name := entry.Level.String()
+			dst = levelColor(theme, entry.Level).Append(dst, name)
+			if l.align {
+				padding = paddingOf(name, levelWidth)
+			}
+		case "group":
+			dst = theme.Group.Append(dst, entry.Group)
+			if l.align {
+				padding = paddingOf(entry.Group, l.groupWidth)
+			}
+		case "caller":
+			dst = theme.Caller.Append(dst, caller)
+		case "message":
+			dst = theme.Message.Append(dst, entry.Message)
+		case "fields":
+			if l.fieldFormat == FieldFormatJSON {
+				// The JSON object is added below the line, once it is complete.
+				below = appendJSONFields(below, theme, rendered)
+				continue
+			}
+			dst = appendKeyValueFields(dst, theme, rendered)
+		}
+	}
+
+	// Placeholders rendering nothing, such as {caller} when the entry has no
+	// caller, would otherwise leave trailing spaces.
+	dst = dst[:start+len(bytes.TrimRight(dst[start:], " "))]
+	dst = append(dst, below...)
+	if stack != "" {
+		dst = append(dst, "\n\t"...)
+		dst = theme.Caller.Append(dst, strings.ReplaceAll(stack, "\n", "\n\t"))
+	}
+
+	return dst
+}
+
+// split returns the fields rendered by the {fields} placeholder, the caller
+// rendered by the {caller} placeholder and the stack trace rendered below the
+// line. The caller is left out of the fields when the layout renders it with
+// the {caller} placeholder, and rendered with the caller path otherwise.
+func (l *textLayout) split(fields Fields) (rendered Fields, caller string, stack string) {
+	special := false
+	for _, f := range fields {
+		special = special || f.Key == callerKey || f.Key == stackKey
+	}
+	if !special {
+		return fields, "", ""
+	}
+
+	rendered = make(Fields, 0, len(fields))
+	for _, f := range fields {
+		switch {
+		case f.Key == stackKey && f.Type == StringType:
+			stack = f.String
+		case f.Key == callerKey && f.Type == StringType:
+			caller = f.String
+			if l.callerPath == CallerPathShort {
+				caller = shortCaller(caller)
+			}
+			if !l.caller {
+				rendered = append(rendered, String(callerKey, caller))
+			}
+		default:
+			rendered = append(rendered, f)
+		}
+	}
+
+	return rendered, caller, stack
+}
+
+// appendKeyValueFields appends the fields as {key=value ...}, or nothing if
+// there are none.
+func appendKeyValueFields(dst []byte, theme *console.Theme, fields Fields) []byte {
+	if len(fields) == 0 {
+		return dst
+	}
+
+	dst = append(dst, '{')
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ' ')
+		}
+		dst = theme.Key.Append(dst, f.Key)
+		dst = append(dst, '=')
+		dst = theme.Value.Append(dst, f.Text())
+	}
+
+	return append(dst, '}')
+}
+
+// appendJSONFields appends the fields as an indented JSON object on the lines
+// following the line, or nothing if there are none.
+func appendJSONFields(dst []byte, theme *console.Theme, fields Fields) []byte {
+	if len(fields) == 0 {
+		return dst
+	}
+
+	// Fields that cannot be marshalled are rendered as text instead.
+	dst = append(dst, '\n')
+	b, err := fields.AppendJSON(nil)
+	if err != nil {
+		return theme.Value.Append(dst, fields.Text())
+	}
+	var indented bytes.Buffer
+	json.Indent(&indented, b, "", "  ")
+
+	return theme.Value.Append(dst, indented.String())
+}
+
+// paddingOf returns the number of spaces that pad s to the given width.
+func paddingOf(s string, width int) int {
+	return max(width-utf8.RuneCountInString(s), 0)
+}
+
+// appendSpaces appends n spaces.
+func appendSpaces(dst []byte, n int) []byte {
+	for ; n > 0; n-- {
+		dst = append(dst, ' ')
+	}
+
+	return dst
+}
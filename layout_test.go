@@ -0,0 +1,112 @@
+package multilog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mateothegreat/multilog/console"
+)
+
+func TestTextLayout(t *testing.T) {
+	at := time.Date(2024, 7, 4, 19, 3, 19, 500_000_000, time.FixedZone("EST", -5*3600))
+	entry := &Entry{
+		Time:    at,
+		Level:   WARN,
+		Group:   "api",
+		Message: "request failed",
+		Fields:  Fields{String("caller", "api/handler.go:42"), Int("status", 502)},
+	}
+
+	for _, tt := range []struct {
+		name  string
+		args  *NewConsoleLoggerArgs
+		entry *Entry
+		want  string
+	}{
+		{
+			name: "default",
+			args: &NewConsoleLoggerArgs{},
+			want: "2024/07/04 19:03:19 [WARN] api: request failed {caller=api/handler.go:42 status=502}",
+		},
+		{
+			name: "custom",
+			args: &NewConsoleLoggerArgs{Layout: "{level} {time} {caller} {group} > {message} {fields}", TimeLayout: time.Kitchen, UTC: true},
+			want: "WARN 12:03AM api/handler.go:42 api > request failed {status=502}",
+		},
+		{
+			name: "aligned",
+			args: &NewConsoleLoggerArgs{Layout: "[{level}] {group} {message}", Align: true, GroupWidth: 6},
+			want: "[WARN]  api    request failed",
+		},
+		{
+			name: "aligned default",
+			args: &NewConsoleLoggerArgs{Align: true, GroupWidth: 6, UTC: true},
+			want: "2024/07/05 00:03:19 [WARN]  api:    request failed {caller=api/handler.go:42 status=502}",
+		},
+		{
+			name: "json fields",
+			args: &NewConsoleLoggerArgs{Layout: "{level} {message} {fields}", FieldFormat: FieldFormatJSON},
+			want: "WARN request failed\n{\n  \"caller\": \"api/handler.go:42\",\n  \"status\": 502\n}",
+		},
+		{
+			name:  "short caller",
+			args:  &NewConsoleLoggerArgs{Layout: "{caller} {message} {fields}"},
+			entry: &Entry{Message: "request failed", Fields: Fields{String("caller", "/src/app/api/handler.go:42")}},
+			want:  "api/handler.go:42 request failed",
+		},
+		{
+			name:  "long caller",
+			args:  &NewConsoleLoggerArgs{Layout: "{message} {fields}", CallerPath: CallerPathLong},
+			entry: &Entry{Message: "request failed", Fields: Fields{String("caller", "/src/app/api/handler.go:42")}},
+			want:  "request failed {caller=/src/app/api/handler.go:42}",
+		},
+		{
+			name: "stack",
+			args: &NewConsoleLoggerArgs{Layout: "{message} {fields}"},
+			entry: &Entry{Message: "request failed", Fields: Fields{
+				Int("status", 502),
+				String("stack", "main.handle\n\t/src/app/main.go:10\nmain.main\n\t/src/app/main.go:4"),
+			}},
+			want: "request failed {status=502}\n\tmain.handle\n\t\t/src/app/main.go:10\n\tmain.main\n\t\t/src/app/main.go:4",
+		},
+		{
+			name:  "missing caller",
+			args:  &NewConsoleLoggerArgs{Layout: "{message} {caller}"},
+			entry: &Entry{Message: "request failed"},
+			want:  "request failed",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := newTextLayout(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := entry
+			if tt.entry != nil {
+				e = tt.entry
+			}
+			if got := string(layout.append(nil, console.MonochromeTheme(), e)); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextLayout_Errors(t *testing.T) {
+	for _, args := range []*NewConsoleLoggerArgs{
+		{Layout: "{time} {unknown}"},
+		{Layout: "{time"},
+		{FieldFormat: "yaml"},
+		{CallerPath: "relative"},
+	} {
+		if _, err := newTextLayout(args); err == nil {
+			t.Errorf("expected an error for %+v", args)
+		}
+	}
+
+	logger := NewConsoleLogger(&NewConsoleLoggerArgs{Layout: "{nope}"})
+	if err := logger.Setup(); err == nil {
+		t.Error("expected Setup to fail for an invalid layout")
+	}
+}
@@ -0,0 +1,336 @@
+package multilog
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// regexPrefix is the prefix of group patterns holding a regular expression.
+const regexPrefix = "re:"
+
+// maxCachedGroups is the number of groups whose level is cached, so that a
+// program logging to unbounded group names does not grow the cache forever.
+const maxCachedGroups = 4096
+
+// Levels is a base log level with overrides keyed by group pattern, such as
+// TRACE for "db.*" while every other group stays at INFO. All methods are safe
+// for concurrent use, and levels can be changed while messages are logged.
+//
+// A pattern is one of:
+//   - an exact group name, such as "db.postgres";
+//   - a glob, where * matches any sequence of characters and ? a single one,
+//     such as "db.*";
+//   - a regular expression prefixed with "re:", such as "re:^(db|cache)\.".
+//
+// The level of a group is the level of its exact pattern if any, else of the
+// longest matching glob, else of the first matching regular expression in the
+// order they were set, else the base level. Resolved levels are cached until
+// the levels are changed.
+type Levels struct {
+	mu    sync.Mutex                 // mu serializes the changes.
+	state atomic.Pointer[levelState] // state is the current, immutable configuration.
+}
+
+// levelState is an immutable configuration of Levels together with the cache
+// of the levels resolved from it.
+type levelState struct {
+	base      LogLevel
+	overrides map[string]LogLevel // overrides are every pattern keyed as it was set.
+	exact     map[string]LogLevel
+	globs     []levelGlob  // globs are sorted longest first.
+	regexes   []levelRegex // regexes are in the order they were set.
+
+	cache  sync.Map     // cache holds the resolved levels keyed by group.
+	cached atomic.Int64 // cached is the number of groups in the cache.
+}
+
+// levelGlob is a glob pattern and its level.
+type levelGlob struct {
+	pattern string
+	level   LogLevel
+}
+
+// levelRegex is a regular expression pattern and its level.
+type levelRegex struct {
+	pattern string
+	re      *regexp.Regexp
+	level   LogLevel
+}
+
+// NewLevels creates levels with the given base level and no overrides.
+//
+// Arguments:
+//   - base: The level of the groups that match no pattern.
+//
+// Returns:
+//   - *Levels: The new levels.
+func NewLevels(base LogLevel) *Levels {
+	l := &Levels{}
+	l.state.Store(&levelState{base: base})
+
+	return l
+}
+
+// Level returns the level of a group.
+//
+// Arguments:
+//   - group: The group to resolve the level of.
+//
+// Returns:
+//   - LogLevel: The minimum level of the messages logged to the group.
+func (l *Levels) Level(group string) LogLevel {
+	s := l.state.Load()
+	if len(s.overrides) == 0 {
+		return s.base
+	}
+
+	if level, ok := s.cache.Load(group); ok {
+		return level.(LogLevel)
+	}
+
+	level := s.resolve(group)
+	if s.cached.Load() < maxCachedGroups {
+		if _, loaded := s.cache.LoadOrStore(group, level); !loaded {
+			s.cached.Add(1)
+		}
+	}
+
+	return level
+}
+
+// Enabled reports whether a message at the given level is logged to a group.
+func (l *Levels) Enabled(level LogLevel, group string) bool {
+	return level >= l.Level(group)
+}
+
+// Base returns the level of the groups that match no pattern.
+func (l *Levels) Base() LogLevel {
+	return l.state.Load().base
+}
+
+// SetBase sets the level of the groups that match no pattern.
+//
+// Arguments:
+//   - level: The new base level.
+func (l *Levels) SetBase(level LogLevel) {
+	l.update(func(base *LogLevel, overrides map[string]LogLevel) error {
+		*base = level
+		return nil
+	})
+}
+
+// Set sets the level of the groups matching a pattern, replacing any level
+// previously set for the same pattern.
+//
+// Arguments:
+//   - pattern: The exact group, glob or "re:" regular expression to match.
+//   - level: The level of the matching groups.
+//
+// Returns:
+//   - `error` if the pattern is empty or not a valid regular expression.
+//   - `nil` if the level was set.
+func (l *Levels) Set(pattern string, level LogLevel) error {
+	return l.update(func(base *LogLevel, overrides map[string]LogLevel) error {
+		if err := checkPattern(pattern); err != nil {
+			return err
+		}
+		overrides[pattern] = level
+		return nil
+	})
+}
+
+// Remove removes the level set for a pattern.
+//
+// Arguments:
+//   - pattern: The pattern to remove, as it was passed to Set.
+//
+// Returns:
+//   - bool: Whether a level was set for the pattern.
+func (l *Levels) Remove(pattern string) bool {
+	removed := false
+	l.update(func(base *LogLevel, overrides map[string]LogLevel) error {
+		_, removed = overrides[pattern]
+		delete(overrides, pattern)
+		return nil
+	})
+
+	return removed
+}
+
+// Overrides returns the levels set for each pattern.
+//
+// Returns:
+//   - map[string]LogLevel: A copy of the levels keyed by pattern.
+func (l *Levels) Overrides() map[string]LogLevel {
+	s := l.state.Load()
+
+	overrides := make(map[string]LogLevel, len(s.overrides))
+	for pattern, level := range s.overrides {
+		overrides[pattern] = level
+	}
+
+	return overrides
+}
+
+// reset replaces the base level and every override.
+func (l *Levels) reset(base LogLevel, overrides map[string]LogLevel) {
+	l.update(func(b *LogLevel, o map[string]LogLevel) error {
+		*b = base
+		clear(o)
+		for pattern, level := range overrides {
+			o[pattern] = level
+		}
+		return nil
+	})
+}
+
+// update applies a change to a copy of the configuration and swaps it in,
+// which also starts a new, empty cache.
+func (l *Levels) update(change func(base *LogLevel, overrides map[string]LogLevel) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := l.state.Load()
+	base := current.base
+	overrides := make(map[string]LogLevel, len(current.overrides)+1)
+	for pattern, level := range current.overrides {
+		overrides[pattern] = level
+	}
+
+	if err := change(&base, overrides); err != nil {
+		return err
+	}
+
+	s, err := newLevelState(base, overrides, current.regexes)
+	if err != nil {
+		return err
+	}
+	l.state.Store(s)
+
+	return nil
+}
+
+// newLevelState compiles a configuration. Regular expressions keep the order
+// they have in previous, and new ones are added after them in sorted order.
+func newLevelState(base LogLevel, overrides map[string]LogLevel, previous []levelRegex) (*levelState, error) {
+	s := &levelState{
+		base:      base,
+		overrides: overrides,
+		exact:     make(map[string]LogLevel),
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range previous {
+		if level, ok := overrides[r.pattern]; ok {
+			s.regexes = append(s.regexes, levelRegex{pattern: r.pattern, re: r.re, level: level})
+			seen[r.pattern] = true
+		}
+	}
+
+	patterns := make([]string, 0, len(overrides))
+	for pattern := range overrides {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		level := overrides[pattern]
+		switch {
+		case strings.HasPrefix(pattern, regexPrefix):
+			if seen[pattern] {
+				continue
+			}
+			re, err := regexp.Compile(strings.TrimPrefix(pattern, regexPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("error compiling group pattern %s: %w", pattern, err)
+			}
+			s.regexes = append(s.regexes, levelRegex{pattern: pattern, re: re, level: level})
+		case strings.ContainsAny(pattern, "*?"):
+			s.globs = append(s.globs, levelGlob{pattern: pattern, level: level})
+		default:
+			s.exact[pattern] = level
+		}
+	}
+	sort.SliceStable(s.globs, func(i, j int) bool { return len(s.globs[i].pattern) > len(s.globs[j].pattern) })
+
+	return s, nil
+}
+
+// checkPattern returns an error if a group pattern is empty or holds an
+// invalid regular expression.
+func checkPattern(pattern string) error {
+	if pattern == "" || pattern == regexPrefix {
+		return fmt.Errorf("group pattern is empty")
+	}
+
+	if strings.HasPrefix(pattern, regexPrefix) {
+		if _, err := regexp.Compile(strings.TrimPrefix(pattern, regexPrefix)); err != nil {
+			return fmt.Errorf("error compiling group pattern %s: %w", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// resolve returns the level of a group without the cache.
+func (s *levelState) resolve(group string) LogLevel {
+	if level, ok := s.exact[group]; ok {
+		return level
+	}
+	for _, g := range s.globs {
+		if matchGlob(g.pattern, group) {
+			return g.level
+		}
+	}
+	for _, r := range s.regexes {
+		if r.re.MatchString(group) {
+			return r.level
+		}
+	}
+
+	return s.base
+}
+
+// matchGlob reports whether a name matches a glob, where * matches any
+// sequence of characters, including dots, and ? matches a single character.
+func matchGlob(pattern string, name string) bool {
+	// px and nx are the positions in the pattern and the name, and star and
+	// next the positions to backtrack to after the last *.
+	px, nx := 0, 0
+	star, next := -1, 0
+	for nx < len(name) {
+		if px < len(pattern) {
+			switch c := pattern[px]; c {
+			case '*':
+				star, next = px, nx
+				px++
+				continue
+			case '?':
+				px++
+				nx++
+				continue
+			default:
+				if c == name[nx] {
+					px++
+					nx++
+					continue
+				}
+			}
+		}
+		if star < 0 {
+			return false
+		}
+		px = star + 1
+		next++
+		nx = next
+	}
+	for px < len(pattern) && pattern[px] == '*' {
+		px++
+	}
+
+	return px == len(pattern)
+}
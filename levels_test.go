@@ -0,0 +1,194 @@
+package multilog
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLevels_Level(t *testing.T) {
+	l := NewLevels(INFO)
+	for _, override := range []struct {
+		pattern string
+		level   LogLevel
+	}{
+		{"db.*", TRACE},
+		{"db.postgres.*", DEBUG},
+		{"db.postgres.pool", ERROR},
+		{"re:^cache\\.(a|b)$", WARN},
+		{"re:^cache", FATAL},
+		{"http.?", DEBUG},
+	} {
+		if err := l.Set(override.pattern, override.level); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for group, want := range map[string]LogLevel{
+		"":                  INFO,
+		"api":               INFO,
+		"db":                INFO,
+		"db.mysql":          TRACE,
+		"db.postgres.query": DEBUG,
+		"db.postgres.pool":  ERROR,
+		"cache.a":           WARN, // Both regexes match, and the first one set applies.
+		"cache.c":           FATAL,
+		"http.1":            DEBUG,
+		"http.10":           INFO,
+	} {
+		// The second call is served from the cache.
+		for i := 0; i < 2; i++ {
+			if got := l.Level(group); got != want {
+				t.Errorf("Level(%q) = %d, want %d", group, got, want)
+			}
+		}
+	}
+}
+
+func TestLevels_Update(t *testing.T) {
+	l := NewLevels(INFO)
+	if l.Enabled(DEBUG, "db.query") {
+		t.Fatal("DEBUG should be disabled before the override is set")
+	}
+
+	if err := l.Set("db.*", DEBUG); err != nil {
+		t.Fatal(err)
+	}
+	if !l.Enabled(DEBUG, "db.query") {
+		t.Error("DEBUG should be enabled for db.query after the override is set")
+	}
+
+	l.SetBase(ERROR)
+	if l.Enabled(WARN, "api") || !l.Enabled(DEBUG, "db.query") {
+		t.Error("the base level should only apply to groups without an override")
+	}
+
+	if !l.Remove("db.*") || l.Remove("db.*") {
+		t.Error("Remove should report whether the pattern was set")
+	}
+	if l.Enabled(DEBUG, "db.query") {
+		t.Error("DEBUG should be disabled after the override is removed")
+	}
+
+	if err := l.Set("re:(", DEBUG); err == nil {
+		t.Error("expected an error for an invalid regular expression")
+	}
+	if err := l.Set("", DEBUG); err == nil {
+		t.Error("expected an error for an empty pattern")
+	}
+	if len(l.Overrides()) != 0 {
+		t.Errorf("failed updates should not change the overrides: %v", l.Overrides())
+	}
+}
+
+func TestLevels_RegexOrder(t *testing.T) {
+	l := NewLevels(INFO)
+	l.Set("re:^b", ERROR)
+	l.Set("re:^a", DEBUG)
+	l.Set("re:^a", WARN) // Replacing a pattern keeps its position.
+
+	l.Set("re:^ab", TRACE)
+	if got := l.Level("ab"); got != WARN {
+		t.Errorf("Level(ab) = %d, want the level of the first regex set (%d)", got, WARN)
+	}
+}
+
+func TestLevels_Concurrent(t *testing.T) {
+	l := NewLevels(INFO)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				l.Enabled(DEBUG, "db.query")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Set("db.*", DEBUG)
+				l.Remove("db.*")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tt := range []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"db.*", "db.", true},
+		{"db.*", "db.a.b", true},
+		{"db.*", "db", false},
+		{"*.pool", "db.postgres.pool", true},
+		{"*.pool", "db.pool.x", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*", "", true},
+	} {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMultilog_Levels(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string][]string{}
+	record := func(name string, levels *Levels) *CustomLogger {
+		return &CustomLogger{
+			Levels: levels,
+			Log: func(entry *Entry) error {
+				mu.Lock()
+				defer mu.Unlock()
+				got[name] = append(got[name], entry.Group+": "+entry.Message)
+				return nil
+			},
+		}
+	}
+
+	m := NewMultilog(&NewMultilogArgs{Level: INFO})
+	if err := m.Levels().Set("db.*", TRACE); err != nil {
+		t.Fatal(err)
+	}
+
+	// The quiet sink only receives errors, except from the db.postgres group.
+	quiet := NewLevels(ERROR)
+	quiet.Set("db.postgres", DEBUG)
+	m.RegisterLogger("all", record("all", nil))
+	m.RegisterLogger("quiet", record("quiet", quiet))
+
+	m.Trace("db.postgres", "query")
+	m.Trace("api", "dropped")
+	m.Info("api", "request")
+	m.Error("api", "failed")
+
+	want := map[string][]string{
+		"all":   {"db.postgres: query", "api: request", "api: failed"},
+		"quiet": {"api: failed"},
+	}
+	for name, messages := range want {
+		if len(got[name]) != len(messages) {
+			t.Errorf("unexpected messages for %s: %v", name, got[name])
+			continue
+		}
+		for i := range messages {
+			if got[name][i] != messages[i] {
+				t.Errorf("unexpected messages for %s: %v", name, got[name])
+			}
+		}
+	}
+
+	// Changing the levels at runtime applies to the next message.
+	m.Levels().SetBase(ERROR)
+	m.Info("api", "dropped")
+	if len(got["all"]) != 3 {
+		t.Errorf("unexpected messages after raising the base level: %v", got["all"])
+	}
+}
@@ -1,11 +1,9 @@
 package multilog
 
-import (
-	"os"
-	"sync"
-)
+import "context"
 
-// Trace logs a trace message to all registered loggers at the TRACE level.
+// Trace logs a trace message to all loggers registered on the default
+// Multilog at the TRACE level.
 //
 // This function is concurrently called for each logger, so it is safe to call
 // from multiple goroutines without blocking.
@@ -14,20 +12,13 @@ import (
 //
 //   - group: The group name
 //   - message: The message to log
-//   - v: The data to log
-func Trace(group string, message string, v map[string]interface{}) {
-	wg := sync.WaitGroup{}
-	for _, logger := range Loggers {
-		wg.Add(1)
-		go func(logger *CustomLogger) {
-			defer wg.Done()
-			logger.Log(TRACE, group, message, v)
-		}(logger)
-	}
-	wg.Wait()
+//   - fields: The fields to log
+func Trace(group string, message string, fields ...Field) {
+	Default().log(context.Background(), TRACE, group, message, fields)
 }
 
-// Debug logs a debug message to all registered loggers at the DEBUG level.
+// Debug logs a debug message to all loggers registered on the default
+// Multilog at the DEBUG level.
 //
 // This function is concurrently called for each logger, so it is safe to call
 // from multiple goroutines without blocking.
@@ -36,20 +27,13 @@ func Trace(group string, message string, v map[string]interface{}) {
 //
 //   - group: The group name
 //   - message: The message to log
-//   - v: The data to log
-func Debug(group string, message string, v map[string]interface{}) {
-	wg := sync.WaitGroup{}
-	for _, logger := range Loggers {
-		wg.Add(1)
-		go func(logger *CustomLogger) {
-			defer wg.Done()
-			logger.Log(DEBUG, group, message, v)
-		}(logger)
-	}
-	wg.Wait()
+//   - fields: The fields to log
+func Debug(group string, message string, fields ...Field) {
+	Default().log(context.Background(), DEBUG, group, message, fields)
 }
 
-// Info logs an info message to all registered loggers at the INFO level.
+// Info logs an info message to all loggers registered on the default
+// Multilog at the INFO level.
 //
 // This function is concurrently called for each logger, so it is safe to call
 // from multiple goroutines without blocking.
@@ -58,20 +42,13 @@ func Debug(group string, message string, v map[string]interface{}) {
 //
 //   - group: The group name
 //   - message: The message to log
-//   - v: The data to log
-func Info(group string, message string, v map[string]interface{}) {
-	wg := sync.WaitGroup{}
-	for _, logger := range Loggers {
-		wg.Add(1)
-		go func(logger *CustomLogger) {
-			defer wg.Done()
-			logger.Log(INFO, group, message, v)
-		}(logger)
-	}
-	wg.Wait()
+//   - fields: The fields to log
+func Info(group string, message string, fields ...Field) {
+	Default().log(context.Background(), INFO, group, message, fields)
 }
 
-// Warn logs a warn message to all registered loggers at the WARN level.
+// Warn logs a warn message to all loggers registered on the default
+// Multilog at the WARN level.
 //
 // This function is concurrently called for each logger, so it is safe to call
 // from multiple goroutines without blocking.
@@ -80,20 +57,13 @@ func Info(group string, message string, v map[string]interface{}) {
 //
 //   - group: The group name
 //   - message: The message to log
-//   - v: The data to log
-func Warn(group string, message string, v map[string]interface{}) {
-	wg := sync.WaitGroup{}
-	for _, logger := range Loggers {
-		wg.Add(1)
-		go func(logger *CustomLogger) {
-			defer wg.Done()
-			logger.Log(WARN, group, message, v)
-		}(logger)
-	}
-	wg.Wait()
+//   - fields: The fields to log
+func Warn(group string, message string, fields ...Field) {
+	Default().log(context.Background(), WARN, group, message, fields)
 }
 
-// Error logs an error message to all registered loggers at the ERROR level.
+// Error logs an error message to all loggers registered on the default
+// Multilog at the ERROR level.
 //
 // This function is concurrently called for each logger, so it is safe to call
 // from multiple goroutines without blocking.
@@ -102,20 +72,14 @@ func Warn(group string, message string, v map[string]interface{}) {
 //
 //   - group: The group name
 //   - message: The message to log
-//   - v: The data to log
-func Error(group string, message string, v map[string]interface{}) {
-	wg := sync.WaitGroup{}
-	for _, logger := range Loggers {
-		wg.Add(1)
-		go func(logger *CustomLogger) {
-			defer wg.Done()
-			logger.Log(ERROR, group, message, v)
-		}(logger)
-	}
-	wg.Wait()
+//   - fields: The fields to log
+func Error(group string, message string, fields ...Field) {
+	Default().log(context.Background(), ERROR, group, message, fields)
 }
 
-// Fatal logs a fatal message to all registered loggers at the FATAL level.
+// Fatal logs a fatal message to all loggers registered on the default
+// Multilog at the FATAL level, shuts the loggers down so that the message is
+// written out and then exits the process with status code 1.
 //
 // This function is concurrently called for each logger, so it is safe to call
 // from multiple goroutines without blocking.
@@ -124,16 +88,123 @@ func Error(group string, message string, v map[string]interface{}) {
 //
 //   - group: The group name
 //   - message: The message to log
-//   - v: The data to log
-func Fatal(group string, message string, v map[string]interface{}) {
-	wg := sync.WaitGroup{}
-	for _, logger := range Loggers {
-		wg.Add(1)
-		go func(logger *CustomLogger) {
-			defer wg.Done()
-			logger.Log(FATAL, group, message, v)
-		}(logger)
-	}
-	wg.Wait()
-	os.Exit(1)
+//   - fields: The fields to log
+func Fatal(group string, message string, fields ...Field) {
+	Default().Fatal(group, message, fields...)
+}
+
+// Log logs a message to all loggers registered on the default Multilog at the
+// given level, which may be a custom level registered with RegisterLevel.
+// Unlike Fatal, it never exits the process.
+//
+// Arguments:
+//
+//   - level: The log level
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func Log(level LogLevel, group string, message string, fields ...Field) {
+	Default().log(context.Background(), level, group, message, fields)
+}
+
+// TraceCtx logs a trace message to all loggers registered on the default
+// Multilog at the TRACE level, adding the fields attached to ctx with NewContext
+// and the fields returned by the context extractors.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func TraceCtx(ctx context.Context, group string, message string, fields ...Field) {
+	Default().log(ctx, TRACE, group, message, fields)
+}
+
+// DebugCtx logs a debug message to all loggers registered on the default
+// Multilog at the DEBUG level, adding the fields attached to ctx with NewContext
+// and the fields returned by the context extractors.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func DebugCtx(ctx context.Context, group string, message string, fields ...Field) {
+	Default().log(ctx, DEBUG, group, message, fields)
+}
+
+// InfoCtx logs an info message to all loggers registered on the default
+// Multilog at the INFO level, adding the fields attached to ctx with NewContext
+// and the fields returned by the context extractors.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func InfoCtx(ctx context.Context, group string, message string, fields ...Field) {
+	Default().log(ctx, INFO, group, message, fields)
+}
+
+// WarnCtx logs a warn message to all loggers registered on the default
+// Multilog at the WARN level, adding the fields attached to ctx with NewContext
+// and the fields returned by the context extractors.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func WarnCtx(ctx context.Context, group string, message string, fields ...Field) {
+	Default().log(ctx, WARN, group, message, fields)
+}
+
+// ErrorCtx logs an error message to all loggers registered on the default
+// Multilog at the ERROR level, adding the fields attached to ctx with NewContext
+// and the fields returned by the context extractors.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func ErrorCtx(ctx context.Context, group string, message string, fields ...Field) {
+	Default().log(ctx, ERROR, group, message, fields)
+}
+
+// FatalCtx logs a fatal message to all loggers registered on the default
+// Multilog at the FATAL level, adding the fields attached to ctx with
+// NewContext and the fields returned by the context extractors. It then shuts
+// the loggers down so that the message is written out and exits the process
+// with status code 1.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func FatalCtx(ctx context.Context, group string, message string, fields ...Field) {
+	Default().FatalCtx(ctx, group, message, fields...)
+}
+
+// LogCtx logs a message to all loggers registered on the default Multilog at
+// the given level, adding the fields attached to ctx with NewContext and the
+// fields returned by the context extractors. Unlike FatalCtx, it never exits
+// the process.
+//
+// Arguments:
+//
+//   - ctx: The context of the call
+//   - level: The log level
+//   - group: The group name
+//   - message: The message to log
+//   - fields: The fields to log
+func LogCtx(ctx context.Context, level LogLevel, group string, message string, fields ...Field) {
+	Default().log(ctx, level, group, message, fields)
 }
@@ -0,0 +1,543 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+const (
+	// DefaultBatchSize is the BatchSize used when NewElasticsearchLoggerArgs.BatchSize is not set.
+	DefaultBatchSize = 500
+	// DefaultBatchBytes is the BatchBytes used when NewElasticsearchLoggerArgs.BatchBytes is not set.
+	DefaultBatchBytes = 5 << 20
+	// DefaultFlushInterval is the FlushInterval used when NewElasticsearchLoggerArgs.FlushInterval is not set.
+	DefaultFlushInterval = time.Second
+	// DefaultConcurrency is the Concurrency used when NewElasticsearchLoggerArgs.Concurrency is not set.
+	DefaultConcurrency = 1
+	// DefaultQueueBatches is the QueueBatches used when NewElasticsearchLoggerArgs.QueueBatches is not set.
+	DefaultQueueBatches = 4
+)
+
+// ErrQueueFull is reported when full batches are logged faster than the
+// workers send them, for example while every worker is backing off from an
+// unreachable cluster, and there is no SpillDir to write them to. The documents
+// of the batch are dropped.
+var ErrQueueFull = errors.New("elasticsearch bulk queue is full")
+
+// BulkItemError is a document that the cluster rejected in a bulk request.
+type BulkItemError struct {
+	Index    string // Index is the index the document was sent to.
+	Status   int    // Status is the HTTP status code of the item.
+	Type     string // Type is the elasticsearch error type, such as mapper_parsing_exception.
+	Reason   string // Reason is the elasticsearch error reason.
+	Document []byte // Document is the JSON document that was rejected.
+}
+
+// BulkError is returned when one or more documents of a bulk request were rejected.
+type BulkError struct {
+	Items []BulkItemError // Items are the rejected documents.
+}
+
+// Error implements the error interface.
+func (e *BulkError) Error() string {
+	if len(e.Items) == 0 {
+		return "bulk request failed"
+	}
+
+	first := e.Items[0]
+	return fmt.Sprintf("%d document(s) rejected by bulk request, first: status %d: %s: %s", len(e.Items), first.Status, first.Type, first.Reason)
+}
+
+// bulkItem is a document waiting to be sent in a bulk request.
+type bulkItem struct {
+	op       string // op is the bulk action, index or create.
+	index    string // index is the index the document is sent to.
+	document []byte // document is the JSON document.
+}
+
+// bulkResponse is the part of the bulk API response needed to find rejected documents.
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]bulkResponseItem `json:"items"`
+}
+
+// bulkResponseItem is the result of a single document in a bulk API response.
+type bulkResponseItem struct {
+	Status int `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// bulkIndexer buffers documents and sends them to the cluster in batches using
+// the bulk API. A batch is sent once it reaches the configured number of
+// documents or bytes, or when the flush interval elapses.
+type bulkIndexer struct {
+	client     *elasticsearch.Client
+	batchSize  int
+	batchBytes int
+	op         string          // op is the bulk action, create for data streams and index otherwise.
+	retry      retrier         // retry is the retry policy for failed documents.
+	spool      *spool          // spool stores the documents that failed after the retries, nil when spilling is disabled.
+	onError    func(err error) // onError handles the errors of batches sent in the background.
+
+	mu     sync.Mutex
+	items  []bulkItem // items are the buffered documents.
+	bytes  int        // bytes is the size of the buffered documents.
+	closed bool       // closed is set once the indexer no longer accepts documents and batches.
+
+	done        context.Context    // done is cancelled once the indexer starts closing.
+	cancel      context.CancelFunc // cancel cancels done.
+	dispatching sync.WaitGroup     // dispatching tracks the batches being handed to the workers.
+	batches     chan []bulkItem    // batches are consumed by the workers.
+	workers     sync.WaitGroup     // workers tracks the worker goroutines.
+
+	pendingMu sync.Mutex
+	pending   int             // pending is the number of batches handed to the workers that have not been sent yet.
+	idle      []chan struct{} // idle are closed once pending drops to zero.
+	stop      chan struct{}   // stop is closed to stop the retries and the replay.
+}
+
+// errClosed is returned when a document is logged after the logger was closed.
+var errClosed = errors.New("elasticsearch logger is closed")
+
+// newBulkIndexer creates a bulk indexer and starts its workers and flush timer,
+// and the replay of spilled documents when spilling is enabled.
+func newBulkIndexer(client *elasticsearch.Client, args *NewElasticsearchLoggerArgs, onError func(err error)) (*bulkIndexer, error) {
+	b := &bulkIndexer{
+		client:     client,
+		batchSize:  args.BatchSize,
+		batchBytes: args.BatchBytes,
+		op:         "index",
+		retry:      newRetrier(args),
+		onError:    onError,
+		stop:       make(chan struct{}),
+	}
+	b.done, b.cancel = context.WithCancel(context.Background())
+	if args.DataStream {
+		b.op = "create"
+	}
+	if args.SpillDir != "" {
+		spool, err := newSpool(args.SpillDir)
+		if err != nil {
+			return nil, err
+		}
+		b.spool = spool
+	}
+	if b.batchSize <= 0 {
+		b.batchSize = DefaultBatchSize
+	}
+	if b.batchBytes <= 0 {
+		b.batchBytes = DefaultBatchBytes
+	}
+
+	queue := args.QueueBatches
+	if queue <= 0 {
+		queue = DefaultQueueBatches
+	}
+	b.batches = make(chan []bulkItem, queue)
+
+	concurrency := args.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	for i := 0; i < concurrency; i++ {
+		b.workers.Add(1)
+		go b.work()
+	}
+
+	interval := args.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	go b.tick(interval)
+
+	if b.spool != nil {
+		replayInterval := args.ReplayInterval
+		if replayInterval <= 0 {
+			replayInterval = DefaultReplayInterval
+		}
+		b.workers.Add(1)
+		go b.replay(replayInterval)
+	}
+
+	return b, nil
+}
+
+// add buffers a document, handing the buffered batch to the workers once it
+// is full. It never waits for the workers, so that logging does not block
+// while the cluster is slow or unreachable.
+func (b *bulkIndexer) add(index string, document []byte) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errClosed
+	}
+	b.items = append(b.items, bulkItem{op: b.op, index: index, document: document})
+	b.bytes += len(document)
+	var batch []bulkItem
+	if len(b.items) >= b.batchSize || b.bytes >= b.batchBytes {
+		batch = b.take()
+	}
+	b.mu.Unlock()
+
+	return b.offer(batch)
+}
+
+// take removes and returns the buffered documents. The caller must hold b.mu.
+func (b *bulkIndexer) take() []bulkItem {
+	if len(b.items) == 0 {
+		return nil
+	}
+
+	batch := b.items
+	b.items = nil
+	b.bytes = 0
+
+	return batch
+}
+
+// acquire registers a batch being handed to the workers, so that close waits
+// for it before closing the batches channel. The caller must call
+// b.dispatching.Done once the batch has been handed over.
+//
+// Returns:
+//   - `false` if the indexer is closed.
+func (b *bulkIndexer) acquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return false
+	}
+	b.dispatching.Add(1)
+
+	return true
+}
+
+// dispatch hands a batch to the workers, blocking while all of them are busy.
+// When ctx is done or the indexer closes first, the batch is spilled to disk
+// if spilling is enabled, and dropped otherwise.
+func (b *bulkIndexer) dispatch(ctx context.Context, batch []bulkItem) error {
+	if batch == nil {
+		return nil
+	}
+	if !b.acquire() {
+		return b.overflow(batch, errClosed)
+	}
+	defer b.dispatching.Done()
+
+	b.begin()
+	select {
+	case b.batches <- batch:
+		return nil
+	case <-ctx.Done():
+		b.end()
+		return b.overflow(batch, ctx.Err())
+	case <-b.done.Done():
+		b.end()
+		return b.overflow(batch, errClosed)
+	}
+}
+
+// offer hands a batch to the workers without blocking. When the queue of
+// batches is full, the batch is spilled to disk if spilling is enabled, and
+// dropped otherwise.
+//
+// Returns:
+//   - `error` wrapping ErrQueueFull if the batch was dropped, or the error of
+//     writing it to disk.
+//   - `nil` if the batch was queued or spilled.
+func (b *bulkIndexer) offer(batch []bulkItem) error {
+	if batch == nil {
+		return nil
+	}
+
+	if !b.acquire() {
+		return b.overflow(batch, errClosed)
+	}
+	defer b.dispatching.Done()
+
+	b.begin()
+	select {
+	case b.batches <- batch:
+		return nil
+	default:
+		b.end()
+		return b.overflow(batch, ErrQueueFull)
+	}
+}
+
+// overflow spills a batch that could not be handed to the workers to disk if
+// spilling is enabled, and drops it otherwise.
+//
+// Arguments:
+//   - batch: the documents that could not be handed to the workers.
+//   - cause: the reason, wrapped by the error returned when the batch is dropped.
+//
+// Returns:
+//   - `error` wrapping cause if the batch was dropped, or the error of writing
+//     it to disk.
+//   - `nil` if the batch was spilled.
+func (b *bulkIndexer) overflow(batch []bulkItem, cause error) error {
+	if b.spool == nil {
+		return fmt.Errorf("dropped %d document(s): %w", len(batch), cause)
+	}
+	if err := b.spool.write(batch); err != nil {
+		return fmt.Errorf("dropped %d document(s) (%v): %w", len(batch), cause, err)
+	}
+
+	return nil
+}
+
+// begin records that a batch has been handed to the workers.
+func (b *bulkIndexer) begin() {
+	b.pendingMu.Lock()
+	b.pending++
+	b.pendingMu.Unlock()
+}
+
+// end records that a batch has been sent and releases the flush calls
+// waiting for the workers to become idle.
+func (b *bulkIndexer) end() {
+	b.pendingMu.Lock()
+	defer b.pendingMu.Unlock()
+
+	b.pending--
+	if b.pending == 0 {
+		for _, idle := range b.idle {
+			close(idle)
+		}
+		b.idle = nil
+	}
+}
+
+// wait waits until every batch handed to the workers has been sent.
+func (b *bulkIndexer) wait(ctx context.Context) error {
+	b.pendingMu.Lock()
+	if b.pending == 0 {
+		b.pendingMu.Unlock()
+		return nil
+	}
+	idle := make(chan struct{})
+	b.idle = append(b.idle, idle)
+	b.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush sends the buffered documents and waits for every pending batch to be sent.
+func (b *bulkIndexer) flush(ctx context.Context) error {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if err := b.dispatch(ctx, batch); err != nil {
+		return err
+	}
+
+	return b.wait(ctx)
+}
+
+// close flushes the buffered documents and stops the workers and flush timer.
+// The buffered documents are spilled to disk, or dropped, when ctx is done
+// before a worker takes them.
+func (b *bulkIndexer) close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	batch := b.take()
+	b.mu.Unlock()
+
+	// Stop the flush timer and give up the dispatches waiting for a busy
+	// worker, which return at once, before sending the last batch.
+	b.cancel()
+	b.dispatching.Wait()
+
+	var err error
+	if batch != nil {
+		b.begin()
+		select {
+		case b.batches <- batch:
+		case <-ctx.Done():
+			b.end()
+			err = b.overflow(batch, ctx.Err())
+		}
+	}
+	err = errors.Join(err, b.wait(ctx))
+
+	// The workers, including the replay, exit once the channel and stop are closed.
+	close(b.stop)
+	close(b.batches)
+
+	done := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+		return err
+	}
+}
+
+// tick sends the buffered documents every interval until the indexer is closed.
+func (b *bulkIndexer) tick(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.mu.Lock()
+			batch := b.take()
+			b.mu.Unlock()
+
+			if err := b.dispatch(b.done, batch); err != nil {
+				b.onError(err)
+			}
+		case <-b.done.Done():
+			return
+		}
+	}
+}
+
+// work sends batches until the batches channel is closed.
+func (b *bulkIndexer) work() {
+	defer b.workers.Done()
+
+	for batch := range b.batches {
+		if err := b.process(batch); err != nil {
+			b.onError(err)
+		}
+		b.end()
+	}
+}
+
+// process sends a batch, retrying the documents that failed with a retryable
+// error and spilling them to disk once the retries are exhausted.
+//
+// Returns:
+//   - `error` joining the *BulkError of rejected documents and the errors of
+//     documents that could neither be sent nor spilled.
+//   - `nil` if every document was indexed or spilled.
+func (b *bulkIndexer) process(batch []bulkItem) error {
+	failed, err := b.retry.send(b.stop, batch, b.send)
+	if len(failed) == 0 {
+		return err
+	}
+
+	if b.spool == nil {
+		return errors.Join(err, fmt.Errorf("dropped %d document(s) after exhausting retries", len(failed)))
+	}
+	if spillErr := b.spool.write(failed); spillErr != nil {
+		return errors.Join(err, fmt.Errorf("dropped %d document(s) after exhausting retries: %w", len(failed), spillErr))
+	}
+
+	return err
+}
+
+// send sends a batch using the bulk API.
+//
+// Returns:
+//   - retry: The documents that failed with a retryable error, which is every
+//     document when the request itself failed.
+//   - rejected: The documents that were rejected with a non-retryable error.
+//   - err: The error of the request, if it failed.
+func (b *bulkIndexer) send(batch []bulkItem) (retry []bulkItem, rejected []BulkItemError, err error) {
+	body := bytes.Buffer{}
+	for _, item := range batch {
+		if err := writeBulkItem(&body, item); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	res, err := b.client.Bulk(&body)
+	if err != nil {
+		return batch, nil, fmt.Errorf("error sending bulk request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		err := fmt.Errorf("error response from bulk request: %s", res.String())
+		if retryableStatus(res.StatusCode) {
+			return batch, nil, err
+		}
+		for _, item := range batch {
+			rejected = append(rejected, BulkItemError{Index: item.index, Status: res.StatusCode, Reason: err.Error(), Document: item.document})
+		}
+		return nil, rejected, nil
+	}
+
+	response := bulkResponse{}
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, nil, fmt.Errorf("error decoding bulk response: %w", err)
+	}
+	if !response.Errors {
+		return nil, nil, nil
+	}
+
+	for i, item := range response.Items {
+		if i >= len(batch) {
+			break
+		}
+		for _, result := range item {
+			if result.Status < 300 {
+				continue
+			}
+			if retryableStatus(result.Status) {
+				retry = append(retry, batch[i])
+				continue
+			}
+			itemErr := BulkItemError{
+				Index:    batch[i].index,
+				Status:   result.Status,
+				Document: batch[i].document,
+			}
+			if result.Error != nil {
+				itemErr.Type = result.Error.Type
+				itemErr.Reason = strings.TrimSpace(result.Error.Reason)
+			}
+			rejected = append(rejected, itemErr)
+		}
+	}
+
+	return retry, rejected, nil
+}
+
+// writeBulkItem writes the action and document lines of a bulk request item.
+func writeBulkItem(w *bytes.Buffer, item bulkItem) error {
+	action, err := json.Marshal(map[string]map[string]string{item.op: {"_index": item.index}})
+	if err != nil {
+		return fmt.Errorf("error marshalling bulk action: %w", err)
+	}
+	w.Write(action)
+	w.WriteByte('\n')
+	w.Write(item.document)
+	w.WriteByte('\n')
+
+	return nil
+}
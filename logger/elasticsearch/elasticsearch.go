@@ -2,91 +2,113 @@ package elasticsearch
 
 import (
 	"bytes"
-	"encoding/json"
-	"log"
+	"context"
+	"fmt"
+	"os"
 	"regexp"
-	"time"
+	"sync"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/mateothegreat/multilog"
 )
 
 // Setup is the method to setup the elasticsearch logger.
-func (l *ElasticsearchLogger) Setup() {
+func (l *ElasticsearchLogger) Setup() error {
 	client, err := elasticsearch.NewClient(l.args.Config)
 	if err != nil {
-		log.Fatalf("error creating elasticsearch client: %s", err)
+		return fmt.Errorf("error creating elasticsearch client: %w", err)
 	}
 	l.client = client
 
+	if l.args.IndexTemplate != nil && l.args.IndexTemplateName == "" {
+		return fmt.Errorf("index template name is required when an index template is provided")
+	}
+	if l.args.ILMPolicy != nil && l.args.ILMPolicyName == "" {
+		return fmt.Errorf("ILM policy name is required when an ILM policy is provided")
+	}
+	l.index, err = parseIndexName(l.args.Index)
+	if err != nil {
+		return err
+	}
+
 	// Compile the filter patterns if provided.
 	for _, pattern := range l.args.FilterDropPatterns {
 		if pattern != nil {
 			compiledPattern, err := regexp.Compile(*pattern)
 			if err != nil {
-				log.Fatalf("error compiling filter pattern: %s", err)
+				return fmt.Errorf("error compiling filter pattern: %w", err)
 			}
 			l.filterPatterns = append(l.filterPatterns, compiledPattern)
 		}
 	}
 
-	// If the mapping is not provided, we assume that the index already exists.
-	if l.args.Mapping == nil {
-		// Check if the index already exists.
-		existsRes, err := l.client.Indices.Exists([]string{l.args.Index})
-		if err != nil {
-			log.Fatalf("error checking if index exists: %s", err)
-		}
-		defer existsRes.Body.Close()
-
-		// Index does not exist, create it.
-		if existsRes.StatusCode == 404 {
-			if l.args.Mapping != nil {
-				createRes, err := l.client.Indices.Create(l.args.Index,
-					l.client.Indices.Create.WithBody(bytes.NewReader([]byte(*l.args.Mapping))))
-				if err != nil {
-					log.Fatalf("error creating index with mapping: %s", err)
-				}
-				defer createRes.Body.Close()
-
-				if createRes.IsError() {
-					log.Fatalf("error response from creating index: %s", createRes.String())
-				}
-			}
+	l.encoder = l.args.Encoder
+	if l.encoder == nil {
+		l.encoder = multilog.JSONEncoder{Timestamp: l.args.DataStream}
+	}
+
+	if err := l.bootstrap(); err != nil {
+		return err
+	}
+
+	onError := l.args.ErrorHandler
+	if onError == nil {
+		onError = func(err error) {
+			fmt.Fprintf(os.Stderr, "multilog: elasticsearch: %s\n", err)
 		}
 	}
+	l.bulk, err = newBulkIndexer(l.client, l.args, onError)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
-// Log is the method to log a message to the elasticsearch cluster.
-func (l *ElasticsearchLogger) Log(level multilog.LogLevel, group string, message string, v map[string]interface{}) {
-	// Check if the log level is sufficient to log the message.
-	if level < l.args.Level {
-		return // Drop the message if the log level is lower than the configured level.
-	}
+// bufferPool holds the buffers documents are encoded into.
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
 
+// Log is the method to log a message to the elasticsearch cluster.
+//
+// The message is buffered and sent with the next bulk request. Errors from the
+// bulk request are passed to the ErrorHandler.
+func (l *ElasticsearchLogger) Log(entry *multilog.Entry) error {
 	// Check if the message matches any of the filter patterns.
 	for _, pattern := range l.filterPatterns {
-		if pattern.MatchString(group) || pattern.MatchString(message) {
-			return // Drop the message if it matches any of the filter patterns.
+		if pattern.MatchString(entry.Group) || pattern.MatchString(entry.Message) {
+			return nil // Drop the message if it matches any of the filter patterns.
 		}
 	}
 
-	data, err := json.Marshal(ElasticsearchLog{
-		Time:    time.Now(),
-		Level:   level,
-		Group:   group,
-		Message: message,
-		Data:    v,
-	})
-	if err != nil {
-		log.Fatalf("error marshalling document: %s", err)
-	}
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
 
-	res, err := l.client.Index(l.args.Index, bytes.NewReader(data))
-	if err != nil {
-		log.Fatalf("error indexing document: %s", err)
+	var err error
+	if *buf, err = l.encoder.Encode((*buf)[:0], entry); err != nil {
+		return err
 	}
-	defer res.Body.Close()
+
+	// The document is copied out of the pooled buffer since it stays buffered
+	// until its bulk request has been sent.
+	document := bytes.Clone(bytes.TrimRight(*buf, "\n"))
+
+	return l.bulk.add(l.index.resolve(entry.Time), document)
+}
+
+// Flush is the method to send the buffered messages to the elasticsearch cluster
+// and wait for every pending bulk request to complete.
+func (l *ElasticsearchLogger) Flush(ctx context.Context) error {
+	return l.bulk.flush(ctx)
+}
+
+// Close is the method to flush the buffered messages and stop sending bulk requests.
+func (l *ElasticsearchLogger) Close(ctx context.Context) error {
+	return l.bulk.close(ctx)
 }
 
 // NewElasticsearchLogger creates a new elasticsearch logger.
@@ -102,7 +124,10 @@ func NewElasticsearchLogger(args *NewElasticsearchLoggerArgs) *multilog.CustomLo
 	}
 
 	return &multilog.CustomLogger{
-		Setup: logger.Setup,
-		Log:   logger.Log,
+		Setup:  logger.Setup,
+		Log:    logger.Log,
+		Flush:  logger.Flush,
+		Close:  logger.Close,
+		Levels: multilog.NewLevels(args.Level),
 	}
 }
@@ -0,0 +1,532 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+)
+
+// fakeCluster is an in-process stand-in for the parts of the elasticsearch
+// API used by the logger.
+type fakeCluster struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests [][]string             // requests are the NDJSON lines of each bulk request.
+	reject   func(line string) bool // reject reports whether a document should be rejected.
+	throttle int                    // throttle is the number of upcoming bulk requests answered with a 429.
+	down     bool                   // down answers every request with a 503.
+	calls    []string               // calls are the method and path of every other request.
+	missing  map[string]bool        // missing are the paths answered with a 404 to GET and HEAD requests.
+}
+
+func newFakeCluster(t *testing.T) *fakeCluster {
+	c := &fakeCluster{}
+	c.Server = httptest.NewServer(http.HandlerFunc(c.serve))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func (c *fakeCluster) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("X-Elastic-Product", "Elasticsearch")
+	w.Header().Set("Content-Type", "application/json")
+
+	c.mu.Lock()
+	down := c.down
+	c.mu.Unlock()
+	if down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error":"unavailable"}`))
+		return
+	}
+
+	if r.URL.Path != "/_bulk" {
+		c.mu.Lock()
+		c.calls = append(c.calls, r.Method+" "+r.URL.Path)
+		missing := c.missing[r.URL.Path] && (r.Method == http.MethodGet || r.Method == http.MethodHead)
+		c.mu.Unlock()
+
+		if missing {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+		return
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	c.mu.Lock()
+	c.requests = append(c.requests, lines)
+	reject := c.reject
+	throttled := c.throttle > 0
+	if throttled {
+		c.throttle--
+	}
+	c.mu.Unlock()
+
+	if throttled {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"too many requests"}`))
+		return
+	}
+
+	items := []string{}
+	failed := false
+	for i := 1; i < len(lines); i += 2 {
+		if reject != nil && reject(lines[i]) {
+			failed = true
+			items = append(items, `{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}`)
+		} else {
+			items = append(items, `{"index":{"status":201}}`)
+		}
+	}
+	if failed {
+		w.Write([]byte(`{"errors":true,"items":[` + strings.Join(items, ",") + `]}`))
+	} else {
+		w.Write([]byte(`{"errors":false,"items":[` + strings.Join(items, ",") + `]}`))
+	}
+}
+
+func (c *fakeCluster) setDown(down bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.down = down
+}
+
+func (c *fakeCluster) bulkRequests() [][]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([][]string(nil), c.requests...)
+}
+
+func TestElasticsearchLogger_Bulk(t *testing.T) {
+	cluster := newFakeCluster(t)
+
+	logger := NewElasticsearchLogger(&NewElasticsearchLoggerArgs{
+		Config:        Config{Addresses: []string{cluster.URL}},
+		Index:         "logs",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, message := range []string{"one", "two", "three"} {
+		if err := logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: message, Time: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := cluster.bulkRequests()
+	if len(requests) != 2 || len(requests[0]) != 4 || len(requests[1]) != 2 {
+		t.Fatalf("unexpected bulk requests: %v", requests)
+	}
+	if requests[0][0] != `{"index":{"_index":"logs"}}` || !strings.Contains(requests[0][1], `"message":"one"`) {
+		t.Errorf("unexpected first bulk item: %v", requests[0][:2])
+	}
+
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "closed", Time: time.Now()}); err == nil {
+		t.Error("expected an error logging after Close")
+	}
+}
+
+func TestElasticsearchLogger_BulkItemErrors(t *testing.T) {
+	cluster := newFakeCluster(t)
+	cluster.reject = func(line string) bool { return strings.Contains(line, "bad") }
+
+	errs := make(chan error, 1)
+	logger := NewElasticsearchLogger(&NewElasticsearchLoggerArgs{
+		Config:       Config{Addresses: []string{cluster.URL}},
+		Index:        "logs",
+		ErrorHandler: func(err error) { errs <- err },
+	})
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "good", Time: time.Now()})
+	logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "bad", Time: time.Now()})
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	bulkErr := &BulkError{}
+	if err := <-errs; !errors.As(err, &bulkErr) {
+		t.Fatalf("got %v, want a *BulkError", err)
+	}
+	if len(bulkErr.Items) != 1 || bulkErr.Items[0].Status != 400 || !strings.Contains(string(bulkErr.Items[0].Document), `"message":"bad"`) {
+		t.Errorf("unexpected rejected items: %+v", bulkErr.Items)
+	}
+}
+
+func TestElasticsearchLogger_Retry(t *testing.T) {
+	cluster := newFakeCluster(t)
+	cluster.throttle = 2
+
+	logger := NewElasticsearchLogger(&NewElasticsearchLoggerArgs{
+		Config:       Config{Addresses: []string{cluster.URL}, DisableRetry: true},
+		Index:        "logs",
+		RetryBackoff: time.Millisecond,
+		ErrorHandler: func(err error) { t.Errorf("unexpected error: %s", err) },
+	})
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "message", Time: time.Now()})
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests := cluster.bulkRequests(); len(requests) != 3 {
+		t.Errorf("got %d bulk requests, want 3", len(requests))
+	}
+}
+
+func TestElasticsearchLogger_SpillAndReplay(t *testing.T) {
+	cluster := newFakeCluster(t)
+	cluster.setDown(true)
+	dir := t.TempDir()
+
+	args := &NewElasticsearchLoggerArgs{
+		Config:         Config{Addresses: []string{cluster.URL}, DisableRetry: true},
+		Index:          "logs",
+		MaxRetries:     1,
+		RetryBackoff:   time.Millisecond,
+		SpillDir:       dir,
+		ReplayInterval: 10 * time.Millisecond,
+		ErrorHandler:   func(err error) {},
+	}
+	logger := NewElasticsearchLogger(args)
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "during maintenance", Time: time.Now()})
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("got %d spill files, want 1", len(files))
+	}
+
+	cluster.setDown(false)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if files, _ := os.ReadDir(dir); len(files) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("spilled documents were not replayed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := cluster.bulkRequests()
+	last := requests[len(requests)-1]
+	if len(last) != 2 || !strings.Contains(last[1], `"message":"during maintenance"`) {
+		t.Errorf("unexpected replayed bulk request: %v", last)
+	}
+}
+
+func TestElasticsearchLogger_QueueFull(t *testing.T) {
+	for _, spill := range []bool{false, true} {
+		cluster := newFakeCluster(t)
+		cluster.setDown(true)
+		dir := t.TempDir()
+
+		args := &NewElasticsearchLoggerArgs{
+			Config:          Config{Addresses: []string{cluster.URL}, DisableRetry: true},
+			Index:           "logs",
+			BatchSize:       1,
+			QueueBatches:    1,
+			MaxRetries:      100,
+			RetryBackoff:    10 * time.Second,
+			RetryMaxBackoff: 10 * time.Second,
+			ReplayInterval:  time.Hour,
+			ErrorHandler:    func(err error) {},
+		}
+		if spill {
+			args.SpillDir = dir
+		}
+		logger := NewElasticsearchLogger(args)
+		if err := logger.Setup(); err != nil {
+			t.Fatal(err)
+		}
+
+		// The worker backs off with the first batch and the queue holds the
+		// second one, so the next ones must be spilled or dropped at once.
+		start := time.Now()
+		var errs []error
+		for i := 0; i < 5; i++ {
+			if err := logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "message", Time: time.Now()}); err != nil {
+				errs = append(errs, err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		if elapsed := time.Since(start); elapsed > 80*time.Millisecond {
+			t.Errorf("spill=%t: logging blocked for %s", spill, elapsed)
+		}
+
+		files, _ := os.ReadDir(dir)
+		if spill && (len(errs) != 0 || len(files) < 3) {
+			t.Errorf("spill=%t: got errors %v and %d spill files", spill, errs, len(files))
+		}
+		if !spill && (len(errs) < 3 || !errors.Is(errs[0], ErrQueueFull)) {
+			t.Errorf("spill=%t: got errors %v, want at least 3 ErrQueueFull", spill, errs)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		logger.Close(ctx)
+		cancel()
+	}
+}
+
+func TestParseIndexName(t *testing.T) {
+	at := time.Date(2024, 7, 5, 16, 55, 52, 0, time.FixedZone("PDT", -7*60*60))
+
+	for _, tc := range []struct {
+		name string
+		want string
+	}{
+		{"logs", "logs"},
+		{"logs-{yyyy.MM.dd}", "logs-2024.07.05"},
+		{"logs-{yyyy.MM.dd}-{HH}", "logs-2024.07.05-23"},
+		{"app2-{yy-MM}", "app2-24-07"},
+	} {
+		n, err := parseIndexName(tc.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := n.resolve(at); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	for _, name := range []string{"logs-{yyyy", "logs-{week}"} {
+		if _, err := parseIndexName(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestElasticsearchLogger_Bootstrap(t *testing.T) {
+	template := `{"index_patterns":["logs*"],"data_stream":{}}`
+	policy := `{"policy":{"phases":{"delete":{"min_age":"30d","actions":{"delete":{}}}}}}`
+	mapping := `{"mappings":{}}`
+
+	for _, tc := range []struct {
+		name  string
+		args  NewElasticsearchLoggerArgs
+		want  []string
+		index string
+	}{
+		{
+			name:  "mapping",
+			args:  NewElasticsearchLoggerArgs{Index: "logs", Mapping: &mapping},
+			want:  []string{"HEAD /logs", "PUT /logs"},
+			index: `{"index":{"_index":"logs"}}`,
+		},
+		{
+			name: "data stream",
+			args: NewElasticsearchLoggerArgs{
+				Index:             "logs-app",
+				DataStream:        true,
+				IndexTemplate:     &template,
+				IndexTemplateName: "logs",
+				ILMPolicy:         &policy,
+				ILMPolicyName:     "logs",
+			},
+			want:  []string{"PUT /_ilm/policy/logs", "PUT /_index_template/logs", "GET /_data_stream/logs-app", "PUT /_data_stream/logs-app"},
+			index: `{"create":{"_index":"logs-app"}}`,
+		},
+		{
+			name:  "date pattern",
+			args:  NewElasticsearchLoggerArgs{Index: "logs-{yyyy}", Mapping: &mapping},
+			want:  nil,
+			index: `{"index":{"_index":"logs-` + time.Now().UTC().Format("2006") + `"}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := newFakeCluster(t)
+			cluster.missing = map[string]bool{"/logs": true, "/_data_stream/logs-app": true}
+
+			args := tc.args
+			args.Config = Config{Addresses: []string{cluster.URL}}
+			logger := NewElasticsearchLogger(&args)
+			if err := logger.Setup(); err != nil {
+				t.Fatal(err)
+			}
+
+			logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "message", Time: time.Now()})
+			if err := logger.Close(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(cluster.calls, ", ") != strings.Join(tc.want, ", ") {
+				t.Errorf("got calls %v, want %v", cluster.calls, tc.want)
+			}
+			requests := cluster.bulkRequests()
+			if len(requests) != 1 || requests[0][0] != tc.index {
+				t.Fatalf("unexpected bulk requests: %v", requests)
+			}
+			if hasTimestamp := strings.Contains(requests[0][1], `"@timestamp"`); hasTimestamp != args.DataStream {
+				t.Errorf("unexpected document: %s", requests[0][1])
+			}
+		})
+	}
+}
+
+func TestElasticsearchLogger_BootstrapError(t *testing.T) {
+	mapping := `{"mappings":{}}`
+
+	for _, tc := range []struct {
+		name string
+		args NewElasticsearchLoggerArgs
+		want string
+	}{
+		{name: "mapping", args: NewElasticsearchLoggerArgs{Index: "logs", Mapping: &mapping}, want: "503"},
+		{name: "data stream", args: NewElasticsearchLoggerArgs{Index: "logs-app", DataStream: true}, want: "unavailable"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := newFakeCluster(t)
+			cluster.setDown(true)
+
+			args := tc.args
+			args.Config = Config{Addresses: []string{cluster.URL}, DisableRetry: true}
+			err := NewElasticsearchLogger(&args).Setup()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got error %v, want one holding %q", err, tc.want)
+			}
+		})
+	}
+}
+
+// benchmarkEntry returns an entry with the common field types.
+func benchmarkEntry() *multilog.Entry {
+	return &multilog.Entry{
+		Time:    time.Date(2024, 7, 4, 19, 3, 19, 123456789, time.UTC),
+		Level:   multilog.INFO,
+		Group:   "http",
+		Message: "request handled",
+		Fields: multilog.Fields{
+			multilog.String("method", "GET"),
+			multilog.String("path", "/api/v1/users/42"),
+			multilog.Int("status", 200),
+			multilog.Bool("cached", true),
+			multilog.Object("user", multilog.String("id", "42"), multilog.String("name", "bob")),
+		},
+	}
+}
+
+func BenchmarkDocument(b *testing.B) {
+	entry := benchmarkEntry()
+
+	b.Run("encoder", func(b *testing.B) {
+		encoder := multilog.JSONEncoder{}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := bufferPool.Get().(*[]byte)
+			*buf, _ = encoder.Encode((*buf)[:0], entry)
+			bufferPool.Put(buf)
+		}
+	})
+
+	// The path documents used to take, marshalling the fields as a map with encoding/json.
+	b.Run("marshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			json.Marshal(ElasticsearchLog{
+				Time:    entry.Time,
+				Level:   entry.Level,
+				Group:   entry.Group,
+				Message: entry.Message,
+				Data:    entry.Fields.Map(),
+			})
+		}
+	})
+}
+
+func TestElasticsearchLogger_CloseDeadline(t *testing.T) {
+	cluster := newFakeCluster(t)
+	cluster.setDown(true)
+
+	logger := NewElasticsearchLogger(&NewElasticsearchLoggerArgs{
+		Config:          Config{Addresses: []string{cluster.URL}, DisableRetry: true},
+		Index:           "logs",
+		QueueBatches:    1,
+		FlushInterval:   time.Millisecond,
+		MaxRetries:      100,
+		RetryBackoff:    10 * time.Second,
+		RetryMaxBackoff: 10 * time.Second,
+		ErrorHandler:    func(err error) {},
+	})
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The worker backs off with the first batch, the queue holds the second
+	// one and the flush timer blocks with the third one.
+	for i := 0; i < 4; i++ {
+		if err := logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "message", Time: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	logged := make(chan error, 1)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		logged <- logger.Log(&multilog.Entry{Level: multilog.INFO, Group: "test", Message: "closing", Time: time.Now()})
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := logger.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the deadline", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("close ignored the deadline for %s", elapsed)
+	}
+
+	select {
+	case err := <-logged:
+		if !errors.Is(err, errClosed) {
+			t.Errorf("got error %v logging while closing, want errClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("logging blocked while closing")
+	}
+}
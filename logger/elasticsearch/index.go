@@ -0,0 +1,208 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+)
+
+// dateTokens maps the date tokens supported in index name patterns to Go time
+// layouts, longest first so that yyyy is matched before yy.
+var dateTokens = []struct {
+	token  string
+	layout string
+}{
+	{"yyyy", "2006"},
+	{"yy", "06"},
+	{"MM", "01"},
+	{"dd", "02"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// indexName is an index name that may contain date patterns in braces, such
+// as logs-{yyyy.MM.dd}, which are resolved from the time of each document.
+type indexName struct {
+	name     string         // name is the index name as configured.
+	segments []indexSegment // segments are the parts of the name, nil when it has no date pattern.
+}
+
+// indexSegment is either literal text or a date pattern of an index name.
+type indexSegment struct {
+	literal string // literal is the literal text of the segment.
+	layout  string // layout is the Go time layout of a date pattern segment.
+}
+
+// parseIndexName parses an index name, converting its date patterns to Go time layouts.
+func parseIndexName(name string) (indexName, error) {
+	n := indexName{name: name}
+	if !strings.Contains(name, "{") {
+		return n, nil
+	}
+
+	for rest := name; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			n.segments = append(n.segments, indexSegment{literal: rest})
+			break
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return indexName{}, fmt.Errorf("unterminated date pattern in index name %q", name)
+		}
+		if open > 0 {
+			n.segments = append(n.segments, indexSegment{literal: rest[:open]})
+		}
+
+		layout, err := parseDatePattern(rest[open+1 : open+end])
+		if err != nil {
+			return indexName{}, fmt.Errorf("error parsing index name %q: %w", name, err)
+		}
+		n.segments = append(n.segments, indexSegment{layout: layout})
+
+		rest = rest[open+end+1:]
+	}
+
+	return n, nil
+}
+
+// parseDatePattern converts a date pattern such as yyyy.MM.dd to a Go time layout.
+func parseDatePattern(pattern string) (string, error) {
+	layout := strings.Builder{}
+
+tokens:
+	for rest := pattern; rest != ""; {
+		for _, t := range dateTokens {
+			if strings.HasPrefix(rest, t.token) {
+				layout.WriteString(t.layout)
+				rest = rest[len(t.token):]
+				continue tokens
+			}
+		}
+		if c := rest[0]; (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return "", fmt.Errorf("unsupported date pattern %q", pattern)
+		}
+		layout.WriteByte(rest[0])
+		rest = rest[1:]
+	}
+
+	return layout.String(), nil
+}
+
+// patterned reports whether the index name contains a date pattern.
+func (n indexName) patterned() bool {
+	return n.segments != nil
+}
+
+// resolve returns the index name for a document logged at t, in UTC.
+func (n indexName) resolve(t time.Time) string {
+	if !n.patterned() {
+		return n.name
+	}
+
+	t = t.UTC()
+	name := strings.Builder{}
+	for _, segment := range n.segments {
+		if segment.layout != "" {
+			name.WriteString(t.Format(segment.layout))
+		} else {
+			name.WriteString(segment.literal)
+		}
+	}
+
+	return name.String()
+}
+
+// bootstrap creates the ILM policy, the index template and the index or data
+// stream, according to the logger arguments.
+func (l *ElasticsearchLogger) bootstrap() error {
+	if l.args.ILMPolicy != nil {
+		res, err := l.client.ILM.PutLifecycle(l.args.ILMPolicyName,
+			l.client.ILM.PutLifecycle.WithBody(strings.NewReader(*l.args.ILMPolicy)))
+		if err := checkResponse(res, err, "putting ILM policy"); err != nil {
+			return err
+		}
+	}
+
+	if l.args.IndexTemplate != nil {
+		res, err := l.client.Indices.PutIndexTemplate(l.args.IndexTemplateName, strings.NewReader(*l.args.IndexTemplate))
+		if err := checkResponse(res, err, "putting index template"); err != nil {
+			return err
+		}
+	}
+
+	// Date-patterned indices are created on the first write to each of them,
+	// using the index template for their settings and mappings.
+	if l.index.patterned() {
+		return nil
+	}
+
+	if l.args.DataStream {
+		res, err := l.client.Indices.GetDataStream(l.client.Indices.GetDataStream.WithName(l.index.name))
+		exists, err := checkExists(res, err, "checking if data stream exists")
+		if err != nil || exists {
+			return err
+		}
+
+		res, err = l.client.Indices.CreateDataStream(l.index.name)
+		return checkResponse(res, err, "creating data stream")
+	}
+
+	// If the mapping is not provided, the index is created on the first write
+	// (or is expected to already exist).
+	if l.args.Mapping == nil {
+		return nil
+	}
+
+	res, err := l.client.Indices.Exists([]string{l.index.name})
+	exists, err := checkExists(res, err, "checking if index exists")
+	if err != nil || exists {
+		return err
+	}
+
+	// Index does not exist, create it.
+	res, err = l.client.Indices.Create(l.index.name,
+		l.client.Indices.Create.WithBody(bytes.NewReader([]byte(*l.args.Mapping))))
+	return checkResponse(res, err, "creating index with mapping")
+}
+
+// checkResponse closes the response and returns an error describing what
+// failed if the request failed or the cluster responded with an error.
+func checkResponse(res *esapi.Response, err error, what string) error {
+	if err != nil {
+		return fmt.Errorf("error %s: %w", what, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("error response from %s: %s", what, res.String())
+	}
+
+	return nil
+}
+
+// checkExists closes the response to a request checking if a resource exists.
+//
+// Returns:
+//   - bool: Whether the cluster responded with a 200 rather than a 404.
+//   - `error` if the request failed or the cluster responded with any other status.
+func checkExists(res *esapi.Response, err error, what string) (bool, error) {
+	if err != nil {
+		return false, fmt.Errorf("error %s: %w", what, err)
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("error response from %s: %s", what, res.String())
+	}
+}
@@ -0,0 +1,118 @@
+package elasticsearch
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the MaxRetries used when NewElasticsearchLoggerArgs.MaxRetries is not set.
+	DefaultMaxRetries = 5
+	// DefaultRetryBackoff is the RetryBackoff used when NewElasticsearchLoggerArgs.RetryBackoff is not set.
+	DefaultRetryBackoff = 100 * time.Millisecond
+	// DefaultRetryMaxBackoff is the RetryMaxBackoff used when NewElasticsearchLoggerArgs.RetryMaxBackoff is not set.
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// retrier retries bulk requests with jittered exponential backoff.
+type retrier struct {
+	maxRetries int           // maxRetries is the number of retries after the first attempt.
+	backoff    time.Duration // backoff is the base delay before the first retry.
+	maxBackoff time.Duration // maxBackoff caps the delay between retries.
+}
+
+// newRetrier creates a retrier from the logger arguments.
+func newRetrier(args *NewElasticsearchLoggerArgs) retrier {
+	r := retrier{
+		maxRetries: args.MaxRetries,
+		backoff:    args.RetryBackoff,
+		maxBackoff: args.RetryMaxBackoff,
+	}
+	if r.maxRetries == 0 {
+		r.maxRetries = DefaultMaxRetries
+	} else if r.maxRetries < 0 {
+		r.maxRetries = 0
+	}
+	if r.backoff <= 0 {
+		r.backoff = DefaultRetryBackoff
+	}
+	if r.maxBackoff <= 0 {
+		r.maxBackoff = DefaultRetryMaxBackoff
+	}
+
+	return r
+}
+
+// send calls send for the batch and then again for the documents that failed
+// with a retryable error, until they succeed, the retries are exhausted or
+// stop is closed.
+//
+// Returns:
+//   - failed: The documents that still failed with a retryable error.
+//   - err: A *BulkError for the rejected documents joined with the last
+//     request error, or nil.
+func (r retrier) send(stop <-chan struct{}, batch []bulkItem, send func(batch []bulkItem) ([]bulkItem, []BulkItemError, error)) (failed []bulkItem, err error) {
+	var rejected []BulkItemError
+	var errs []error
+
+	items := batch
+	for attempt := 0; ; attempt++ {
+		retry, rej, sendErr := send(items)
+		rejected = append(rejected, rej...)
+
+		if len(retry) == 0 {
+			errs = append(errs, sendErr)
+			break
+		}
+		if attempt >= r.maxRetries || !r.wait(stop, attempt) {
+			failed = retry
+			errs = append(errs, sendErr)
+			break
+		}
+		items = retry
+	}
+
+	if len(rejected) > 0 {
+		errs = append(errs, &BulkError{Items: rejected})
+	}
+
+	return failed, errors.Join(errs...)
+}
+
+// wait sleeps for the jittered backoff of the given attempt.
+//
+// Returns:
+//   - `false` if stop was closed while waiting.
+//   - `true` otherwise.
+func (r retrier) wait(stop <-chan struct{}, attempt int) bool {
+	timer := time.NewTimer(r.delay(attempt))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// delay returns the backoff for the given attempt using full jitter: a random
+// duration between zero and the exponential backoff, capped at maxBackoff.
+func (r retrier) delay(attempt int) time.Duration {
+	backoff := r.maxBackoff
+	if attempt < 32 {
+		if d := r.backoff << attempt; d > 0 && d < r.maxBackoff {
+			backoff = d
+		}
+	}
+
+	return rand.N(backoff) + 1
+}
+
+// retryableStatus reports whether a request or bulk item that failed with the
+// given HTTP status code should be retried.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
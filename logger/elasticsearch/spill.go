@@ -0,0 +1,210 @@
+package elasticsearch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReplayInterval is the ReplayInterval used when NewElasticsearchLoggerArgs.ReplayInterval is not set.
+const DefaultReplayInterval = 30 * time.Second
+
+// spillExt is the file extension of spill files.
+const spillExt = ".ndjson"
+
+// spool is a file-backed queue of documents that could not be sent to the
+// cluster. Each spill file holds the NDJSON body of a bulk request.
+type spool struct {
+	dir string // dir is the directory the spill files are written to.
+
+	mu  sync.Mutex
+	seq uint64 // seq disambiguates spill files written in the same nanosecond.
+}
+
+// newSpool creates a spool in the given directory, creating it if needed.
+func newSpool(dir string) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("error creating spill directory: %w", err)
+	}
+
+	return &spool{dir: dir}, nil
+}
+
+// write writes the documents to a new spill file.
+func (s *spool) write(items []bulkItem) error {
+	s.mu.Lock()
+	s.seq++
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq, spillExt)
+	s.mu.Unlock()
+
+	return s.rewrite(filepath.Join(s.dir, name), items)
+}
+
+// rewrite atomically replaces the spill file at path with the documents.
+func (s *spool) rewrite(path string, items []bulkItem) error {
+	body := bytes.Buffer{}
+	for _, item := range items {
+		if err := writeBulkItem(&body, item); err != nil {
+			return err
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body.Bytes(), 0o640); err != nil {
+		return fmt.Errorf("error writing spill file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("error writing spill file: %w", err)
+	}
+
+	return nil
+}
+
+// files returns the paths of the spill files, oldest first.
+func (s *spool) files() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading spill directory: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), spillExt) {
+			files = append(files, filepath.Join(s.dir, entry.Name()))
+		}
+	}
+	sort.Strings(files)
+
+	return files, nil
+}
+
+// read reads the documents of the spill file at path.
+func (s *spool) read(path string) ([]bulkItem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening spill file: %w", err)
+	}
+	defer f.Close()
+
+	var items []bulkItem
+	r := bufio.NewReader(f)
+	for {
+		action, err := readLine(r)
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading spill file %s: %w", path, err)
+		}
+		document, err := readLine(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading spill file %s: %w", path, err)
+		}
+
+		meta := map[string]struct {
+			Index string `json:"_index"`
+		}{}
+		if err := json.Unmarshal(action, &meta); err != nil {
+			return nil, fmt.Errorf("error decoding spill file %s: %w", path, err)
+		}
+		for op, m := range meta {
+			items = append(items, bulkItem{op: op, index: m.Index, document: document})
+		}
+	}
+}
+
+// readLine reads a line without its trailing newline.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	line, err := r.ReadBytes('\n')
+	if len(line) > 0 && errors.Is(err, io.EOF) {
+		err = nil // The last line is not terminated by a newline.
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(line, []byte{'\n'}), nil
+}
+
+// replay sends the spilled documents every interval, and once at startup,
+// until the indexer is closed.
+func (b *bulkIndexer) replay(interval time.Duration) {
+	defer b.workers.Done()
+
+	b.replaySpilled()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.replaySpilled()
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// replaySpilled sends the spill files, oldest first, once the cluster is
+// reachable. A spill file is removed once its documents have been sent, or
+// rewritten with the documents that still failed, in which case the replay
+// stops until the next interval.
+func (b *bulkIndexer) replaySpilled() {
+	files, err := b.spool.files()
+	if err != nil {
+		b.onError(err)
+		return
+	}
+	if len(files) == 0 || !b.healthy() {
+		return
+	}
+
+	for _, file := range files {
+		items, err := b.spool.read(file)
+		if err != nil {
+			b.onError(err)
+			continue
+		}
+
+		var remaining []bulkItem
+		for start := 0; start < len(items); start += b.batchSize {
+			end := min(start+b.batchSize, len(items))
+			failed, err := b.retry.send(b.stop, items[start:end], b.send)
+			if err != nil {
+				b.onError(err)
+			}
+			remaining = append(remaining, failed...)
+		}
+
+		if len(remaining) > 0 {
+			if err := b.spool.rewrite(file, remaining); err != nil {
+				b.onError(err)
+			}
+			return
+		}
+		if err := os.Remove(file); err != nil {
+			b.onError(fmt.Errorf("error removing spill file: %w", err))
+		}
+	}
+}
+
+// healthy reports whether the cluster is reachable.
+func (b *bulkIndexer) healthy() bool {
+	res, err := b.client.Ping()
+	if err != nil {
+		return false
+	}
+	defer res.Body.Close()
+
+	return !res.IsError()
+}
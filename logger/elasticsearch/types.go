@@ -8,35 +8,100 @@ import (
 	"github.com/mateothegreat/multilog"
 )
 
-// ElasticsearchLog is the structure of the log that will be sent to the elasticsearch cluster.
-// Data can be any serializable type.
+// ElasticsearchLog is the structure of the documents sent to the elasticsearch cluster, as
+// encoded by the default multilog.JSONEncoder. Data holds the fields of the log as an object.
 type ElasticsearchLog struct {
 	Level   multilog.LogLevel `json:"level"`
 	Group   string            `json:"group"`
 	Message string            `json:"message"`
 	Data    any               `json:"data"`
 	Time    time.Time         `json:"time"`
+	// Timestamp is only set when logging to a data stream, which requires an @timestamp field.
+	Timestamp time.Time `json:"@timestamp,omitzero"`
 }
 
 type Config = elasticsearch.Config
 
 // NewElasticsearchLoggerArgs are the arguments to create a new elasticsearch logger.
 type NewElasticsearchLoggerArgs struct {
-	// Level is the log level to use.
+	// Level is the base of the levels of the returned CustomLogger, which
+	// group patterns set on its Levels can override in either direction.
 	Level multilog.LogLevel
 	// Config is the configuration for the elasticsearch client. https://www.elastic.co/guide/en/elasticsearch/client/go-api/current/connecting.html
 	Config Config
-	// Index is the index to use to send the logs to.
+	// Index is the index, or data stream when DataStream is set, to use to send the logs to.
+	// It can contain a date pattern in braces that is resolved in UTC from the time of each
+	// log, such as "logs-{yyyy.MM.dd}". The supported tokens are yyyy, yy, MM, dd, HH, mm and ss.
 	Index string
-	// Mapping is the mapping for the index.
+	// Mapping is the mapping for the index. When set, the index is created with the mapping if
+	// it does not exist. Ignored for date-patterned indices and data streams, use IndexTemplate
+	// for those instead.
 	Mapping *string
+	// IndexTemplate is the body of a composable index template that is created or updated
+	// during setup. https://www.elastic.co/guide/en/elasticsearch/reference/current/index-templates.html
+	IndexTemplate *string
+	// IndexTemplateName is the name of the index template. Required when IndexTemplate is set.
+	IndexTemplateName string
+	// ILMPolicy is the body of an index lifecycle management policy that is created or updated
+	// during setup, before the index template so that the template can refer to it.
+	// https://www.elastic.co/guide/en/elasticsearch/reference/current/index-lifecycle-management.html
+	ILMPolicy *string
+	// ILMPolicyName is the name of the ILM policy. Required when ILMPolicy is set.
+	ILMPolicyName string
+	// DataStream sends the logs to the data stream named by Index, which is created during
+	// setup if it does not exist. A matching index template with data streams enabled must
+	// exist or be provided with IndexTemplate.
+	DataStream bool
+	// Encoder encodes the documents, for example to produce a different document shape.
+	// It must produce a single JSON object, and a trailing newline is removed. When
+	// DataStream is set the document must hold an @timestamp field. Defaults to a
+	// multilog.JSONEncoder, with Timestamp set for data streams.
+	Encoder multilog.Encoder
 	// FilterDropPatterns is a slice of regex patterns to filter out log messages.
 	FilterDropPatterns []*string
+	// BatchSize is the number of documents sent per bulk request. Defaults to DefaultBatchSize.
+	BatchSize int
+	// BatchBytes is the size in bytes of the documents that triggers a bulk request. Defaults to DefaultBatchBytes.
+	BatchBytes int
+	// FlushInterval is the interval at which buffered documents are sent, regardless of
+	// the batch size. Defaults to DefaultFlushInterval.
+	FlushInterval time.Duration
+	// Concurrency is the number of bulk requests that can be in flight at the same time.
+	// Defaults to DefaultConcurrency.
+	Concurrency int
+	// QueueBatches is the number of full batches that can wait for a worker. Logging never
+	// waits for the workers: once the queue is full, further batches are written to SpillDir,
+	// or dropped when it is not set, in which case Log returns an error wrapping ErrQueueFull.
+	// Defaults to DefaultQueueBatches.
+	QueueBatches int
+	// MaxRetries is the number of times documents that failed with a 429 or 5xx status, or
+	// because the cluster was unreachable, are retried. Defaults to DefaultMaxRetries, set to
+	// a negative value to disable retries.
+	MaxRetries int
+	// RetryBackoff is the base delay before the first retry. The delay doubles with each
+	// retry and is jittered. Defaults to DefaultRetryBackoff.
+	RetryBackoff time.Duration
+	// RetryMaxBackoff caps the delay between retries. Defaults to DefaultRetryMaxBackoff.
+	RetryMaxBackoff time.Duration
+	// SpillDir is the directory documents are written to once their retries are exhausted.
+	// Spilled documents are replayed once the cluster is reachable again, including after a
+	// restart. Leave empty to drop the documents instead.
+	SpillDir string
+	// ReplayInterval is the interval at which spilled documents are replayed.
+	// Defaults to DefaultReplayInterval.
+	ReplayInterval time.Duration
+	// ErrorHandler is called when a bulk request sent in the background fails or some of its
+	// documents are rejected, in which case the error is a *BulkError. Defaults to writing
+	// the error to os.Stderr.
+	ErrorHandler func(err error)
 }
 
 // ElasticsearchLogger is the logger that sends logs to an elasticsearch cluster.
 type ElasticsearchLogger struct {
 	args           *NewElasticsearchLoggerArgs
+	index          indexName
 	client         *elasticsearch.Client
 	filterPatterns []*regexp.Regexp
+	encoder        multilog.Encoder
+	bulk           *bulkIndexer
 }
@@ -0,0 +1,269 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+)
+
+// Setup is the method to setup the file logger, opening the file and starting
+// the background goroutines.
+func (l *FileLogger) Setup() error {
+	if l.args.Path == "" {
+		return errors.New("file path is required")
+	}
+	if l.args.Format == "" {
+		l.args.Format = multilog.FormatJSON
+	}
+	l.encoder = l.args.Encoder
+	if l.encoder == nil {
+		encoder, err := multilog.NewEncoder(l.args.Format)
+		if err != nil {
+			return err
+		}
+		l.encoder = encoder
+	}
+	if l.args.Mode == 0 {
+		l.args.Mode = 0o644
+	}
+
+	// Compile the filter patterns if provided.
+	for _, pattern := range l.args.FilterDropPatterns {
+		if pattern != nil {
+			compiledPattern, err := regexp.Compile(*pattern)
+			if err != nil {
+				return fmt.Errorf("error compiling filter pattern: %w", err)
+			}
+			l.filterPatterns = append(l.filterPatterns, compiledPattern)
+		}
+	}
+
+	l.onError = l.args.ErrorHandler
+	if l.onError == nil {
+		l.onError = func(err error) {
+			fmt.Fprintf(os.Stderr, "multilog: file: %s\n", err)
+		}
+	}
+
+	if err := l.open(time.Now()); err != nil {
+		return err
+	}
+
+	l.mill = make(chan struct{}, 1)
+	l.stop = make(chan struct{})
+	l.wg.Add(1)
+	go l.millRun()
+	// Rotated files left over by a previous run are compressed and removed too.
+	l.wakeMill()
+
+	if !l.args.DisableReopen {
+		l.hangup = make(chan os.Signal, 1)
+		signal.Notify(l.hangup, syscall.SIGHUP)
+		l.wg.Add(1)
+		go l.reopenOnHangup()
+	}
+
+	return nil
+}
+
+// bufferPool holds the buffers lines are encoded into.
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// Log is the method to write a message to the file, rotating it first when
+// it is due.
+func (l *FileLogger) Log(entry *multilog.Entry) error {
+	// Check if the message matches any of the filter patterns.
+	for _, pattern := range l.filterPatterns {
+		if pattern.MatchString(entry.Group) || pattern.MatchString(entry.Message) {
+			return nil // Drop the message if it matches any of the filter patterns.
+		}
+	}
+
+	buf := bufferPool.Get().(*[]byte)
+	defer bufferPool.Put(buf)
+
+	var err error
+	if *buf, err = l.encoder.Encode((*buf)[:0], entry); err != nil {
+		return err
+	}
+	line := *buf
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("file logger is closed")
+	}
+
+	now := time.Now()
+	if l.due(now, int64(len(line))) {
+		if err := l.rotate(now); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing to %s: %w", l.args.Path, err)
+	}
+
+	return nil
+}
+
+// Flush is the method to commit the written messages to stable storage.
+func (l *FileLogger) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing %s: %w", l.args.Path, err)
+	}
+
+	return nil
+}
+
+// Close is the method to stop the background goroutines and close the file.
+func (l *FileLogger) Close(ctx context.Context) error {
+	l.mu.Lock()
+	file := l.file
+	l.file = nil
+	l.mu.Unlock()
+
+	if file == nil {
+		return nil
+	}
+
+	if l.hangup != nil {
+		signal.Stop(l.hangup)
+	}
+	close(l.stop)
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	errs := []error{file.Sync(), file.Close()}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, ctx.Err())
+	}
+
+	return errors.Join(errs...)
+}
+
+// open opens the file for appending, creating it and its directory if needed,
+// and schedules the next time-based rotation. The caller must hold l.mu once
+// the logger is set up.
+func (l *FileLogger) open(now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(l.args.Path), 0o755); err != nil {
+		return fmt.Errorf("error creating directory for %s: %w", l.args.Path, err)
+	}
+
+	file, err := os.OpenFile(l.args.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, l.args.Mode)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", l.args.Path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("error reading the size of %s: %w", l.args.Path, err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+	l.rotateAt = time.Time{}
+	if l.args.RotateEvery > 0 {
+		// A file that already has content was started when it was last written to,
+		// so that a restart does not postpone its rotation.
+		started := now
+		if l.size > 0 {
+			started = info.ModTime()
+		}
+		l.rotateAt = started.Truncate(l.args.RotateEvery).Add(l.args.RotateEvery)
+	}
+
+	return nil
+}
+
+// reopenOnHangup reopens the file every time the process receives SIGHUP,
+// until the logger is closed.
+func (l *FileLogger) reopenOnHangup() {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.hangup:
+		}
+
+		if err := l.reopen(); err != nil {
+			l.onError(err)
+		}
+	}
+}
+
+// reopen closes the file and opens it again at its path, which starts a new
+// file when it has been moved away.
+func (l *FileLogger) reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	// The new file is opened before the previous one is closed so that the
+	// logger keeps writing to the previous file if the path cannot be opened.
+	previous := l.file
+	if err := l.open(time.Now()); err != nil {
+		return err
+	}
+	if err := previous.Close(); err != nil {
+		return fmt.Errorf("error closing the previous %s: %w", l.args.Path, err)
+	}
+
+	return nil
+}
+
+// NewFileLogger creates a new file logger.
+//
+// Arguments:
+//   - args <*NewFileLoggerArgs>: The arguments to create a new file logger.
+//
+// Returns:
+//   - *CustomLogger: The custom logger.
+func NewFileLogger(args *NewFileLoggerArgs) *multilog.CustomLogger {
+	logger := &FileLogger{
+		args: args,
+	}
+
+	return &multilog.CustomLogger{
+		Setup:  logger.Setup,
+		Log:    logger.Log,
+		Flush:  logger.Flush,
+		Close:  logger.Close,
+		Levels: multilog.NewLevels(args.Level),
+	}
+}
@@ -0,0 +1,178 @@
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+)
+
+func setup(t *testing.T, args *NewFileLoggerArgs) *multilog.CustomLogger {
+	logger := NewFileLogger(args)
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Close(context.Background()) })
+
+	return logger
+}
+
+func entry(message string) *multilog.Entry {
+	return &multilog.Entry{
+		Time:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   multilog.WARN,
+		Group:   "api",
+		Message: message,
+		Fields:  multilog.Fields{multilog.Int("status", 200)},
+	}
+}
+
+func TestFile_Formats(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonLogger := setup(t, &NewFileLoggerArgs{Path: filepath.Join(dir, "logs", "app.json")})
+	textLogger := setup(t, &NewFileLoggerArgs{Path: filepath.Join(dir, "app.log"), Format: multilog.FormatText})
+	for _, logger := range []*multilog.CustomLogger{jsonLogger, textLogger} {
+		if err := logger.Log(entry("hello")); err != nil {
+			t.Fatal(err)
+		}
+		if err := logger.Close(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "logs", "app.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Group   string
+		Message string
+		Data    map[string]any
+	}
+	if err := json.Unmarshal(data, &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Group != "api" || log.Message != "hello" || log.Data["status"] != 200.0 {
+		t.Errorf("unexpected JSON log: %s", data)
+	}
+
+	data, err = os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2026-01-02T03:04:05.000Z [WARN] api: hello {status=200}\n"; string(data) != want {
+		t.Errorf("expected text log %q, got %q", want, data)
+	}
+
+	if err := textLogger.Log(entry("closed")); err == nil {
+		t.Error("expected an error logging after Close")
+	}
+}
+
+func TestFile_SizeRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	logger := setup(t, &NewFileLoggerArgs{
+		Path:       path,
+		Format:     multilog.FormatText,
+		MaxSize:    100,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+
+	for i := 0; i < 5; i++ {
+		// Each line is 60 bytes long, so every line after the first rotates the file.
+		if err := logger.Log(entry(strings.Repeat("x", 10))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mill runs in the background, so run it once more to settle.
+	l := &FileLogger{args: &NewFileLoggerArgs{Path: path, MaxBackups: 2, Compress: true}}
+	if err := l.millOnce(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := l.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 rotated files, got %d", len(backups))
+	}
+	for _, b := range backups {
+		if !b.compressed {
+			t.Errorf("expected %s to be compressed", b.path)
+			continue
+		}
+		f, err := os.Open(b.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(gz)
+		f.Close()
+		if !strings.Contains(string(data), "api: xxxxxxxxxx") {
+			t.Errorf("unexpected content in %s: %q", b.path, data)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), "\n") != 1 {
+		t.Errorf("expected the current file to hold 1 line, got %q", data)
+	}
+}
+
+func TestFile_TimeRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	logger := setup(t, &NewFileLoggerArgs{Path: path, RotateEvery: 50 * time.Millisecond})
+	if err := logger.Log(entry("before")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := logger.Log(entry("after")); err != nil {
+		t.Fatal(err)
+	}
+
+	l := &FileLogger{args: &NewFileLoggerArgs{Path: path}}
+	backups, err := l.backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 rotated file, got %d", len(backups))
+	}
+	data, _ := os.ReadFile(backups[0].path)
+	if !strings.Contains(string(data), "before") || strings.Contains(string(data), "after") {
+		t.Errorf("unexpected rotated content: %s", data)
+	}
+}
+
+func TestFile_Allocs(t *testing.T) {
+	logger := setup(t, &NewFileLoggerArgs{Path: filepath.Join(t.TempDir(), "app.log"), Format: multilog.FormatJSON})
+	e := entry("message")
+
+	if allocs := testing.AllocsPerRun(100, func() { logger.Log(e) }); allocs != 0 {
+		t.Errorf("got %.0f allocations per entry, want 0", allocs)
+	}
+}
@@ -0,0 +1,59 @@
+//go:build unix
+
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFile_ReopenOnHangup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	var errs []error
+	logger := setup(t, &NewFileLoggerArgs{Path: path, ErrorHandler: func(err error) { errs = append(errs, err) }})
+	if err := logger.Log(entry("before")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Move the file away as logrotate does and ask the logger to reopen it.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the file to be reopened")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := logger.Log(entry("after")); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, _ := os.ReadFile(path + ".1")
+	current, _ := os.ReadFile(path)
+	if !strings.Contains(string(moved), "before") || !strings.Contains(string(current), "after") {
+		t.Errorf("unexpected content: moved %q, current %q", moved, current)
+	}
+	if len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+}
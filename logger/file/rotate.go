@@ -0,0 +1,231 @@
+package file
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat is the format of the time in the names of rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// compressSuffix is the suffix added to the names of compressed rotated files.
+const compressSuffix = ".gz"
+
+// backup is a rotated file.
+type backup struct {
+	path       string    // path is the path of the rotated file.
+	rotatedAt  time.Time // rotatedAt is when the file was rotated.
+	compressed bool      // compressed reports whether the file is compressed with gzip.
+}
+
+// due reports whether the file must be rotated before writing n bytes. The
+// caller must hold l.mu.
+func (l *FileLogger) due(now time.Time, n int64) bool {
+	if l.args.MaxSize > 0 && l.size > 0 && l.size+n > l.args.MaxSize {
+		return true
+	}
+
+	return !l.rotateAt.IsZero() && !now.Before(l.rotateAt)
+}
+
+// rotate moves the file aside under a name holding the rotation time, opens
+// a new file and wakes the goroutine that compresses and removes rotated
+// files. The caller must hold l.mu.
+func (l *FileLogger) rotate(now time.Time) error {
+	if l.size == 0 {
+		// Nothing was written since the last rotation, so only the next
+		// time-based rotation is scheduled.
+		l.rotateAt = now.Truncate(l.args.RotateEvery).Add(l.args.RotateEvery)
+		return nil
+	}
+
+	if err := l.file.Close(); err != nil {
+		l.onError(fmt.Errorf("error closing %s: %w", l.args.Path, err))
+	}
+
+	var errs []error
+	if err := os.Rename(l.args.Path, l.backupPath(now)); err != nil {
+		errs = append(errs, fmt.Errorf("error rotating %s: %w", l.args.Path, err))
+	}
+	if err := l.open(now); err != nil {
+		errs = append(errs, err)
+	}
+	l.wakeMill()
+
+	return errors.Join(errs...)
+}
+
+// backupPath returns an unused name for a file rotated at the given time, such
+// as app-2006-01-02T15-04-05.000.log for app.log.
+func (l *FileLogger) backupPath(t time.Time) string {
+	dir, prefix, ext := l.nameParts()
+
+	for {
+		path := filepath.Join(dir, prefix+t.UTC().Format(backupTimeFormat)+ext)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if _, err := os.Stat(path + compressSuffix); os.IsNotExist(err) {
+				return path
+			}
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// nameParts splits the path of the file into the directory, the prefix of
+// rotated file names and the extension.
+func (l *FileLogger) nameParts() (dir string, prefix string, ext string) {
+	dir = filepath.Dir(l.args.Path)
+	name := filepath.Base(l.args.Path)
+	ext = filepath.Ext(name)
+
+	return dir, strings.TrimSuffix(name, ext) + "-", ext
+}
+
+// backups returns the rotated files, newest first.
+func (l *FileLogger) backups() ([]backup, error) {
+	dir, prefix, ext := l.nameParts()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error listing rotated files: %w", err)
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		b := backup{path: filepath.Join(dir, name)}
+		if strings.HasSuffix(name, compressSuffix) {
+			b.compressed = true
+			name = strings.TrimSuffix(name, compressSuffix)
+		}
+		if !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		value := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		b.rotatedAt, err = time.Parse(backupTimeFormat, value)
+		if err != nil {
+			continue // Not a file rotated by this logger.
+		}
+		backups = append(backups, b)
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].rotatedAt.After(backups[j].rotatedAt) })
+
+	return backups, nil
+}
+
+// wakeMill wakes the goroutine that compresses and removes rotated files up.
+func (l *FileLogger) wakeMill() {
+	select {
+	case l.mill <- struct{}{}:
+	default:
+	}
+}
+
+// millRun compresses and removes rotated files every time it is woken up,
+// until the logger is closed.
+func (l *FileLogger) millRun() {
+	defer l.wg.Done()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-l.mill:
+		}
+
+		if err := l.millOnce(); err != nil {
+			l.onError(err)
+		}
+	}
+}
+
+// millOnce removes the rotated files beyond MaxBackups or older than MaxAge,
+// and compresses the remaining ones when Compress is set.
+func (l *FileLogger) millOnce() error {
+	backups, err := l.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	cutoff := time.Now().Add(-l.args.MaxAge)
+	for i, b := range backups {
+		expired := l.args.MaxAge > 0 && b.rotatedAt.Before(cutoff)
+		if expired || (l.args.MaxBackups > 0 && i >= l.args.MaxBackups) {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("error removing rotated file: %w", err))
+			}
+			continue
+		}
+
+		if l.args.Compress && !b.compressed {
+			if err := compress(b.path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// compress compresses a rotated file with gzip and removes the original. The
+// compressed file is written under a temporary name first so that a partial
+// file is never mistaken for a rotated one.
+func compress(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error opening rotated file: %w", err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading rotated file: %w", err)
+	}
+
+	tmp := path + compressSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+	if err != nil {
+		return fmt.Errorf("error creating compressed file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return fmt.Errorf("error compressing rotated file: %w", err)
+	}
+	if err = gz.Close(); err != nil {
+		return fmt.Errorf("error compressing rotated file: %w", err)
+	}
+	if err = dst.Close(); err != nil {
+		return fmt.Errorf("error writing compressed file: %w", err)
+	}
+	if err = os.Rename(tmp, path+compressSuffix); err != nil {
+		return fmt.Errorf("error writing compressed file: %w", err)
+	}
+
+	src.Close()
+	if err = os.Remove(path); err != nil {
+		return fmt.Errorf("error removing compressed rotated file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package file
+
+import (
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+)
+
+// NewFileLoggerArgs are the arguments to create a new file logger.
+type NewFileLoggerArgs struct {
+	// Level is the base of the levels of the returned CustomLogger, which
+	// group patterns set on its Levels can override in either direction.
+	Level multilog.LogLevel
+	// Path is the path of the file the logs are written to. Its directory is created if
+	// it does not exist.
+	Path string
+	// Format is the format of the log that is written, one JSON object or text line per
+	// message. Defaults to multilog.FormatJSON.
+	Format multilog.Format
+	// Encoder encodes the entries, such as a multilog.LogfmtEncoder. When set, it takes
+	// precedence over Format. It must produce newline-terminated records for the file to
+	// be readable line by line.
+	Encoder multilog.Encoder
+	// FilterDropPatterns is a slice of regex patterns to filter out log messages.
+	FilterDropPatterns []*string
+	// Mode is the permission of the file when it is created. Defaults to 0644.
+	Mode os.FileMode
+	// MaxSize is the size in bytes the file is rotated at. Zero disables size-based rotation.
+	MaxSize int64
+	// RotateEvery is the interval the file is rotated at, aligned to multiples of the
+	// interval since the zero time in UTC, so that 24 * time.Hour rotates at midnight UTC.
+	// Zero disables time-based rotation.
+	RotateEvery time.Duration
+	// MaxAge is how long rotated files are kept. Zero keeps them regardless of their age.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files that are kept. Zero keeps them all.
+	MaxBackups int
+	// Compress compresses rotated files with gzip.
+	Compress bool
+	// DisableReopen stops the file from being reopened when the process receives SIGHUP,
+	// which lets logrotate move the file and have the logger start a new one.
+	DisableReopen bool
+	// ErrorHandler is called when compressing or removing rotated files, or reopening
+	// the file on SIGHUP, fails. Defaults to writing the error to os.Stderr.
+	ErrorHandler func(err error)
+}
+
+// FileLogger is the logger that writes logs to a file, rotating it by size and time.
+type FileLogger struct {
+	args           *NewFileLoggerArgs
+	filterPatterns []*regexp.Regexp
+	encoder        multilog.Encoder
+	onError        func(err error)
+
+	mu       sync.Mutex
+	file     *os.File  // file is the open file, nil once the logger is closed.
+	size     int64     // size is the size of the file.
+	rotateAt time.Time // rotateAt is when the file is next rotated by time, zero when disabled.
+
+	mill   chan struct{}  // mill wakes the goroutine that compresses and removes rotated files.
+	hangup chan os.Signal // hangup receives SIGHUP, nil when reopening is disabled.
+	stop   chan struct{}  // stop is closed to stop the background goroutines.
+	wg     sync.WaitGroup // wg tracks the background goroutines.
+}
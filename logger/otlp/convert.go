@@ -0,0 +1,136 @@
+package otlp
+
+import (
+	"encoding/hex"
+	"math"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// severity returns the OpenTelemetry severity number and text of a log level.
+// https://opentelemetry.io/docs/specs/otel/logs/data-model/#field-severitynumber
+//
+// The log levels are four apart from TRACE, like the OTLP severity numbers
+// are from SEVERITY_NUMBER_TRACE, so each level maps to the number of its
+// name and custom levels between them to the numbers in between, clamped to
+// the range of TRACE to FATAL4.
+func severity(level multilog.LogLevel) (logspb.SeverityNumber, string) {
+	number := logspb.SeverityNumber_SEVERITY_NUMBER_TRACE + logspb.SeverityNumber(level-multilog.TRACE)
+	number = max(logspb.SeverityNumber_SEVERITY_NUMBER_TRACE, min(number, logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4))
+
+	return number, level.String()
+}
+
+// newRecord converts an entry to an OTLP log record.
+//
+// The trace_id, span_id and trace_flags fields added by multilog.TraceFields
+// populate the record's trace context instead of being added as attributes.
+func newRecord(entry *multilog.Entry) *logspb.LogRecord {
+	number, text := severity(entry.Level)
+
+	record := &logspb.LogRecord{
+		TimeUnixNano:         uint64(entry.Time.UnixNano()),
+		ObservedTimeUnixNano: uint64(time.Now().UnixNano()),
+		SeverityNumber:       number,
+		SeverityText:         text,
+		Body:                 &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: entry.Message}},
+		Attributes:           make([]*commonpb.KeyValue, 0, len(entry.Fields)),
+	}
+
+	for _, f := range entry.Fields {
+		if f.Type == multilog.StringType && setTraceContext(record, f.Key, f.String) {
+			continue
+		}
+		record.Attributes = append(record.Attributes, keyValue(f))
+	}
+
+	return record
+}
+
+// setTraceContext sets the record's trace context from a trace correlation
+// field and reports whether the field was consumed.
+func setTraceContext(record *logspb.LogRecord, key string, value string) bool {
+	switch key {
+	case "trace_id":
+		id, err := hex.DecodeString(value)
+		if err != nil || len(id) != 16 {
+			return false
+		}
+		record.TraceId = id
+	case "span_id":
+		id, err := hex.DecodeString(value)
+		if err != nil || len(id) != 8 {
+			return false
+		}
+		record.SpanId = id
+	case "trace_flags":
+		flags, err := hex.DecodeString(value)
+		if err != nil || len(flags) != 1 {
+			return false
+		}
+		record.Flags = uint32(flags[0])
+	default:
+		return false
+	}
+
+	return true
+}
+
+// newResource creates the resource the logs belong to.
+func newResource(args *NewOTLPLoggerArgs) *resourcepb.Resource {
+	resource := &resourcepb.Resource{}
+	if args.ServiceName != "" {
+		resource.Attributes = append(resource.Attributes, keyValue(multilog.String("service.name", args.ServiceName)))
+	}
+	for _, f := range args.Resource {
+		resource.Attributes = append(resource.Attributes, keyValue(f))
+	}
+
+	return resource
+}
+
+// keyValue converts a field to an OTLP attribute.
+func keyValue(f multilog.Field) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: f.Key, Value: anyValue(f)}
+}
+
+// anyValue converts the value of a field to an OTLP value. Nested objects
+// become key/value lists, and values without an OTLP equivalent are
+// converted to their text representation.
+func anyValue(f multilog.Field) *commonpb.AnyValue {
+	switch f.Type {
+	case multilog.StringType:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: f.String}}
+	case multilog.IntType:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: f.Integer}}
+	case multilog.UintType:
+		if f.Integer < 0 {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: f.Text()}}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: f.Integer}}
+	case multilog.FloatType:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: math.Float64frombits(uint64(f.Integer))}}
+	case multilog.BoolType:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: f.Integer == 1}}
+	case multilog.ObjectType:
+		fields := f.Interface.(multilog.Fields)
+		values := make([]*commonpb.KeyValue, 0, len(fields))
+		for _, nested := range fields {
+			values = append(values, keyValue(nested))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: values}}}
+	case multilog.AnyType:
+		switch v := f.Interface.(type) {
+		case nil:
+			return &commonpb.AnyValue{}
+		case []byte:
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: v}}
+		}
+	}
+
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: f.Text()}}
+}
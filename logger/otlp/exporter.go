@@ -0,0 +1,546 @@
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultEndpoint is the Endpoint used when NewOTLPLoggerArgs.Endpoint is not set.
+	DefaultEndpoint = "http://localhost:4318/v1/logs"
+	// DefaultBatchSize is the BatchSize used when NewOTLPLoggerArgs.BatchSize is not set.
+	DefaultBatchSize = 512
+	// DefaultMaxBufferSize is the MaxBufferSize used when NewOTLPLoggerArgs.MaxBufferSize is not set.
+	DefaultMaxBufferSize = 8192
+	// DefaultFlushInterval is the FlushInterval used when NewOTLPLoggerArgs.FlushInterval is not set.
+	DefaultFlushInterval = time.Second
+	// DefaultTimeout is the timeout of the HTTP client used when NewOTLPLoggerArgs.Client is not set.
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxRetries is the MaxRetries used when NewOTLPLoggerArgs.MaxRetries is not set.
+	DefaultMaxRetries = 5
+	// DefaultRetryBackoff is the RetryBackoff used when NewOTLPLoggerArgs.RetryBackoff is not set.
+	DefaultRetryBackoff = 100 * time.Millisecond
+	// DefaultRetryMaxBackoff is the RetryMaxBackoff used when NewOTLPLoggerArgs.RetryMaxBackoff is not set.
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+// maxResponseSize is the maximum number of bytes read from a collector response.
+const maxResponseSize = 1 << 20
+
+// PartialSuccessError is returned when the collector accepted an export
+// request but rejected some of its log records.
+type PartialSuccessError struct {
+	Rejected int64  // Rejected is the number of log records the collector rejected.
+	Message  string // Message is the explanation given by the collector, if any.
+}
+
+// Error implements the error interface.
+func (e *PartialSuccessError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d log record(s) rejected by the collector", e.Rejected)
+	}
+
+	return fmt.Sprintf("%d log record(s) rejected by the collector: %s", e.Rejected, e.Message)
+}
+
+// retryableError is an export error after which the request can be retried.
+type retryableError struct {
+	err   error         // err is the error of the export request.
+	after time.Duration // after is the delay asked for by the collector, zero when it gave none.
+}
+
+// Error implements the error interface.
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error of the export request.
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// scopedRecord is a log record waiting to be exported together with the
+// instrumentation scope it belongs to.
+type scopedRecord struct {
+	scope  string            // scope is the name of the instrumentation scope, the group of the entry.
+	record *logspb.LogRecord // record is the log record.
+}
+
+// exporter buffers log records and exports them to the collector in batches.
+// A batch is exported once it reaches the configured number of records, or
+// when the flush interval elapses.
+type exporter struct {
+	client        *http.Client
+	endpoint      string
+	encoding      Encoding
+	headers       map[string]string
+	resource      *resourcepb.Resource
+	batchSize     int
+	maxBufferSize int
+	maxRetries    int
+	backoff       time.Duration
+	maxBackoff    time.Duration
+	onError       func(err error) // onError handles the errors of the background exports.
+
+	mu     sync.Mutex
+	buffer []scopedRecord // buffer are the records waiting to be exported, oldest first.
+	closed bool           // closed is set once the exporter no longer accepts records.
+
+	// sending is a semaphore held from the moment a batch is taken from the
+	// buffer until it has been exported, so that flush returns only once every
+	// record taken before it has been exported. It is a channel so that waiting
+	// for it ends with the context.
+	sending chan struct{}
+	kick    chan struct{} // kick wakes the worker up when a full batch is buffered.
+	stop    chan struct{} // stop is closed to stop the worker.
+	done    chan struct{} // done is closed when the worker has exited.
+}
+
+// newExporter creates an exporter and starts its worker.
+func newExporter(args *NewOTLPLoggerArgs, onError func(err error)) (*exporter, error) {
+	e := &exporter{
+		client:        args.Client,
+		endpoint:      args.Endpoint,
+		encoding:      args.Encoding,
+		headers:       args.Headers,
+		resource:      newResource(args),
+		batchSize:     args.BatchSize,
+		maxBufferSize: args.MaxBufferSize,
+		maxRetries:    args.MaxRetries,
+		backoff:       args.RetryBackoff,
+		maxBackoff:    args.RetryMaxBackoff,
+		onError:       onError,
+		sending:       make(chan struct{}, 1),
+		kick:          make(chan struct{}, 1),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	if e.client == nil {
+		e.client = &http.Client{Timeout: DefaultTimeout}
+	}
+	if e.endpoint == "" {
+		e.endpoint = DefaultEndpoint
+	}
+	switch e.encoding {
+	case "":
+		e.encoding = EncodingProtobuf
+	case EncodingProtobuf, EncodingJSON:
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", e.encoding)
+	}
+	if e.batchSize <= 0 {
+		e.batchSize = DefaultBatchSize
+	}
+	if e.maxBufferSize <= 0 {
+		e.maxBufferSize = DefaultMaxBufferSize
+	}
+	switch {
+	case e.maxRetries == 0:
+		e.maxRetries = DefaultMaxRetries
+	case e.maxRetries < 0:
+		e.maxRetries = 0
+	}
+	if e.backoff <= 0 {
+		e.backoff = DefaultRetryBackoff
+	}
+	if e.maxBackoff <= 0 {
+		e.maxBackoff = DefaultRetryMaxBackoff
+	}
+	interval := args.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	go e.work(interval)
+
+	return e, nil
+}
+
+// add buffers a record for export, waking the worker up once a full batch is buffered.
+func (e *exporter) add(scope string, record *logspb.LogRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.closed {
+		return errors.New("otlp logger is closed")
+	}
+	if len(e.buffer) >= e.maxBufferSize {
+		return fmt.Errorf("buffer of %d log records is full, dropping log record", e.maxBufferSize)
+	}
+
+	e.buffer = append(e.buffer, scopedRecord{scope: scope, record: record})
+	if len(e.buffer) >= e.batchSize {
+		select {
+		case e.kick <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// take removes up to a batch of records, and at most limit records, from the buffer.
+func (e *exporter) take(limit int) []scopedRecord {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := min(len(e.buffer), e.batchSize, limit)
+	batch := make([]scopedRecord, n)
+	copy(batch, e.buffer)
+	e.buffer = append(e.buffer[:0], e.buffer[n:]...)
+
+	return batch
+}
+
+// requeue puts a batch that could not be exported back at the front of the
+// buffer, so that the next drain exports it first.
+func (e *exporter) requeue(batch []scopedRecord) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.buffer = append(batch, e.buffer...)
+}
+
+// drain exports the records buffered when it is called, in batches. The
+// records buffered meanwhile are left to the next drain, so that drain returns
+// even while records are logged as fast as they are exported. Once ctx is
+// done, including while waiting for another drain to finish, the batch being
+// exported and the remaining records stay buffered.
+//
+// Returns:
+//   - `error` joining the errors of every batch that failed to be exported, or
+//     the error of ctx if it was done before the export started.
+//   - `nil` if every batch was exported.
+func (e *exporter) drain(ctx context.Context) error {
+	select {
+	case e.sending <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("error waiting for the export in progress: %w", ctx.Err())
+	}
+	defer func() { <-e.sending }()
+
+	e.mu.Lock()
+	remaining := len(e.buffer)
+	e.mu.Unlock()
+
+	var errs []error
+	for remaining > 0 {
+		batch := e.take(remaining)
+		if len(batch) == 0 {
+			break
+		}
+		remaining -= len(batch)
+
+		if err := e.send(ctx, batch); err != nil {
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send exports a batch, retrying it with backoff while it fails with a
+// retryable error, up to the configured number of retries. A batch that ctx
+// interrupts is put back into the buffer.
+func (e *exporter) send(ctx context.Context, batch []scopedRecord) error {
+	for attempt := 0; ; attempt++ {
+		err := e.export(ctx, batch)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			e.requeue(batch)
+			return fmt.Errorf("error exporting %d log record(s): %w", len(batch), ctx.Err())
+		}
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) || attempt >= e.maxRetries {
+			return err
+		}
+
+		// The delay asked for by the collector is capped like the backoff, so
+		// that a collector cannot stall the exports indefinitely.
+		delay := min(retryable.after, e.maxBackoff)
+		if delay <= 0 {
+			delay = e.delay(attempt)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			e.requeue(batch)
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// delay returns the backoff for the given attempt using full jitter: a random
+// duration between zero and the exponential backoff, capped at maxBackoff.
+func (e *exporter) delay(attempt int) time.Duration {
+	backoff := e.maxBackoff
+	if attempt < 32 {
+		if d := e.backoff << attempt; d > 0 && d < e.maxBackoff {
+			backoff = d
+		}
+	}
+
+	return rand.N(backoff) + 1
+}
+
+// flush exports every buffered record.
+func (e *exporter) flush(ctx context.Context) error {
+	return e.drain(ctx)
+}
+
+// close stops accepting records, stops the worker and exports the buffered records.
+func (e *exporter) close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.stop)
+	}
+	e.mu.Unlock()
+
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return e.drain(ctx)
+}
+
+// work exports the buffered records whenever a full batch is buffered or the
+// flush interval elapses, until the exporter is closed. Closing the exporter
+// interrupts the export in progress, leaving its records to close.
+func (e *exporter) work(interval time.Duration) {
+	defer close(e.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-e.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		case <-e.kick:
+		}
+
+		if err := e.drain(ctx); err != nil && ctx.Err() == nil {
+			e.onError(err)
+		}
+	}
+}
+
+// export sends a batch of records to the collector in a single export request,
+// grouping the records by instrumentation scope.
+func (e *exporter) export(ctx context.Context, batch []scopedRecord) error {
+	scopes := make([]*logspb.ScopeLogs, 0, 1)
+	index := make(map[string]*logspb.ScopeLogs)
+	for _, r := range batch {
+		scope, ok := index[r.scope]
+		if !ok {
+			scope = &logspb.ScopeLogs{Scope: &commonpb.InstrumentationScope{Name: r.scope}}
+			index[r.scope] = scope
+			scopes = append(scopes, scope)
+		}
+		scope.LogRecords = append(scope.LogRecords, r.record)
+	}
+
+	request := &collogspb.ExportLogsServiceRequest{
+		ResourceLogs: []*logspb.ResourceLogs{{Resource: e.resource, ScopeLogs: scopes}},
+	}
+
+	body, contentType, err := e.encode(request)
+	if err != nil {
+		return fmt.Errorf("error encoding export request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating export request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range e.headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return &retryableError{err: fmt.Errorf("error exporting %d log record(s): %w", len(batch), err)}
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return fmt.Errorf("error reading export response: %w", err)
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		err := fmt.Errorf("error exporting %d log record(s): collector responded with status %d: %s", len(batch), res.StatusCode, bytes.TrimSpace(data))
+		if retryableStatus(res.StatusCode) {
+			return &retryableError{err: err, after: retryAfter(res.Header.Get("Retry-After"))}
+		}
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+	response := &collogspb.ExportLogsServiceResponse{}
+	if err := e.decode(data, response); err != nil {
+		return fmt.Errorf("error decoding export response: %w", err)
+	}
+	if partial := response.GetPartialSuccess(); partial.GetRejectedLogRecords() > 0 {
+		return &PartialSuccessError{Rejected: partial.GetRejectedLogRecords(), Message: partial.GetErrorMessage()}
+	}
+
+	return nil
+}
+
+// retryableStatus reports whether an export request that failed with the
+// given HTTP status code should be retried, as specified by OTLP/HTTP.
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter parses the delay of a Retry-After header, given either in seconds
+// or as an HTTP date, returning zero when there is none.
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0)
+	}
+
+	return 0
+}
+
+// encode encodes an export request with the configured encoding.
+func (e *exporter) encode(request *collogspb.ExportLogsServiceRequest) ([]byte, string, error) {
+	if e.encoding == EncodingJSON {
+		// OTLP/JSON requires enums as integers, and trace and span IDs as hex
+		// strings rather than the base64 protojson uses for bytes.
+		data, err := protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(request)
+		if err != nil {
+			return nil, "", err
+		}
+		data, err = hexIDs(data)
+		return data, "application/json", err
+	}
+
+	data, err := proto.Marshal(request)
+	return data, "application/x-protobuf", err
+}
+
+// decode decodes an export response with the configured encoding.
+func (e *exporter) decode(data []byte, response *collogspb.ExportLogsServiceResponse) error {
+	if e.encoding == EncodingJSON {
+		return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, response)
+	}
+
+	return proto.Unmarshal(data, response)
+}
+
+// hexIDs rewrites the base64 traceId and spanId of every log record in a
+// JSON export request as hex strings.
+func hexIDs(data []byte) ([]byte, error) {
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, err
+	}
+
+	err := rewrite(request, "resourceLogs", func(resourceLogs map[string]json.RawMessage) error {
+		return rewrite(resourceLogs, "scopeLogs", func(scopeLogs map[string]json.RawMessage) error {
+			return rewrite(scopeLogs, "logRecords", func(record map[string]json.RawMessage) error {
+				for _, key := range []string{"traceId", "spanId"} {
+					raw, ok := record[key]
+					if !ok {
+						continue
+					}
+					var encoded string
+					if err := json.Unmarshal(raw, &encoded); err != nil {
+						return err
+					}
+					id, err := base64.StdEncoding.DecodeString(encoded)
+					if err != nil {
+						return err
+					}
+					record[key], _ = json.Marshal(hex.EncodeToString(id))
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(request)
+}
+
+// rewrite calls fn for each object of the array under the given key of a JSON
+// object and stores the rewritten array back under the key.
+func rewrite(object map[string]json.RawMessage, key string, fn func(map[string]json.RawMessage) error) error {
+	raw, ok := object[key]
+	if !ok {
+		return nil
+	}
+
+	var items []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	object[key] = data
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package otlp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/mateothegreat/multilog"
+)
+
+// Setup is the method to setup the OTLP logger.
+func (l *OTLPLogger) Setup() error {
+	// Compile the filter patterns if provided.
+	for _, pattern := range l.args.FilterDropPatterns {
+		if pattern != nil {
+			compiledPattern, err := regexp.Compile(*pattern)
+			if err != nil {
+				return fmt.Errorf("error compiling filter pattern: %w", err)
+			}
+			l.filterPatterns = append(l.filterPatterns, compiledPattern)
+		}
+	}
+
+	onError := l.args.ErrorHandler
+	if onError == nil {
+		onError = func(err error) {
+			fmt.Fprintf(os.Stderr, "multilog: otlp: %s\n", err)
+		}
+	}
+
+	var err error
+	l.exporter, err = newExporter(l.args, onError)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Log is the method to log a message to the OpenTelemetry collector.
+//
+// The message is converted to a log record whose instrumentation scope is the
+// group of the message, and buffered until the next export request. Errors
+// from the export request are passed to the ErrorHandler.
+func (l *OTLPLogger) Log(entry *multilog.Entry) error {
+	// Check if the message matches any of the filter patterns.
+	for _, pattern := range l.filterPatterns {
+		if pattern.MatchString(entry.Group) || pattern.MatchString(entry.Message) {
+			return nil // Drop the message if it matches any of the filter patterns.
+		}
+	}
+
+	return l.exporter.add(entry.Group, newRecord(entry))
+}
+
+// Flush is the method to export the buffered messages to the OpenTelemetry collector.
+func (l *OTLPLogger) Flush(ctx context.Context) error {
+	return l.exporter.flush(ctx)
+}
+
+// Close is the method to export the buffered messages and stop exporting.
+func (l *OTLPLogger) Close(ctx context.Context) error {
+	return l.exporter.close(ctx)
+}
+
+// NewOTLPLogger creates a new OTLP logger.
+//
+// Arguments:
+//   - args <*NewOTLPLoggerArgs>: The arguments to create a new OTLP logger.
+//
+// Returns:
+//   - *CustomLogger: The custom logger.
+func NewOTLPLogger(args *NewOTLPLoggerArgs) *multilog.CustomLogger {
+	logger := &OTLPLogger{
+		args: args,
+	}
+
+	return &multilog.CustomLogger{
+		Setup:  logger.Setup,
+		Log:    logger.Log,
+		Flush:  logger.Flush,
+		Close:  logger.Close,
+		Levels: multilog.NewLevels(args.Level),
+	}
+}
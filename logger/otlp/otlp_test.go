@@ -0,0 +1,455 @@
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeCollector is an in-process stand-in for the OTLP/HTTP logs endpoint of
+// an OpenTelemetry collector.
+type fakeCollector struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*collogspb.ExportLogsServiceRequest // requests are the decoded protobuf requests.
+	bodies   [][]byte                              // bodies are the raw JSON requests.
+	headers  []http.Header                         // headers are the headers of every request.
+	rejected int64                                 // rejected is reported as a partial success.
+	failures int                                   // failures is the number of upcoming requests answered with a 503.
+	after    string                                // after is the Retry-After header of the 503 responses.
+	attempts int                                   // attempts is the number of requests received.
+	onExport func()                                // onExport is called for every request, before it is answered.
+}
+
+func newFakeCollector(t *testing.T) *fakeCollector {
+	c := &fakeCollector{}
+	c.Server = httptest.NewServer(http.HandlerFunc(c.serve))
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func (c *fakeCollector) serve(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	c.mu.Lock()
+	onExport := c.onExport
+	c.mu.Unlock()
+	if onExport != nil {
+		onExport()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.attempts++
+	if c.failures > 0 {
+		c.failures--
+		if c.after != "" {
+			w.Header().Set("Retry-After", c.after)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	c.headers = append(c.headers, r.Header.Clone())
+	response := &collogspb.ExportLogsServiceResponse{}
+	if c.rejected > 0 {
+		response.PartialSuccess = &collogspb.ExportLogsPartialSuccess{RejectedLogRecords: c.rejected, ErrorMessage: "too old"}
+	}
+
+	if r.Header.Get("Content-Type") == "application/json" {
+		c.bodies = append(c.bodies, body)
+		w.Header().Set("Content-Type", "application/json")
+		if c.rejected > 0 {
+			w.Write([]byte(`{"partialSuccess":{"rejectedLogRecords":"1","errorMessage":"too old"}}`))
+		} else {
+			w.Write([]byte(`{}`))
+		}
+		return
+	}
+
+	request := &collogspb.ExportLogsServiceRequest{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.requests = append(c.requests, request)
+
+	data, _ := proto.Marshal(response)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(data)
+}
+
+func setup(t *testing.T, args *NewOTLPLoggerArgs) *multilog.CustomLogger {
+	logger := NewOTLPLogger(args)
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { logger.Close(context.Background()) })
+
+	return logger
+}
+
+func TestOTLP_Protobuf(t *testing.T) {
+	collector := newFakeCollector(t)
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:    collector.URL + "/v1/logs",
+		Headers:     map[string]string{"Authorization": "Bearer token"},
+		ServiceName: "checkout",
+		BatchSize:   2,
+	})
+
+	now := time.Now()
+	entries := []*multilog.Entry{
+		{Time: now, Level: multilog.WARN, Group: "api", Message: "slow", Fields: multilog.Fields{
+			multilog.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+			multilog.String("span_id", "00f067aa0ba902b7"),
+			multilog.String("trace_flags", "01"),
+			multilog.Int("status", 200),
+			multilog.Object("user", multilog.String("id", "bob")),
+		}},
+		{Time: now, Level: multilog.ERROR, Group: "db", Message: "failed", Fields: multilog.Fields{multilog.Err(errors.New("timeout"))}},
+		{Time: now, Level: multilog.INFO, Group: "api", Message: "done"},
+	}
+	for _, entry := range entries {
+		if err := logger.Log(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	var scopes []string
+	var records []*logspb.LogRecord
+	for _, request := range collector.requests {
+		resource := request.ResourceLogs[0].Resource
+		if resource.Attributes[0].Key != "service.name" || resource.Attributes[0].Value.GetStringValue() != "checkout" {
+			t.Errorf("unexpected resource: %v", resource)
+		}
+		for _, scope := range request.ResourceLogs[0].ScopeLogs {
+			for _, record := range scope.LogRecords {
+				scopes = append(scopes, scope.Scope.Name)
+				records = append(records, record)
+			}
+		}
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(records))
+	}
+	if collector.headers[0].Get("Authorization") != "Bearer token" {
+		t.Errorf("expected the authorization header to be sent")
+	}
+
+	first := records[0]
+	if scopes[0] != "api" || first.SeverityNumber != logspb.SeverityNumber_SEVERITY_NUMBER_WARN || first.SeverityText != "WARN" {
+		t.Errorf("unexpected scope or severity: %s %v %s", scopes[0], first.SeverityNumber, first.SeverityText)
+	}
+	if first.Body.GetStringValue() != "slow" || first.TimeUnixNano != uint64(now.UnixNano()) {
+		t.Errorf("unexpected body or time: %v %d", first.Body, first.TimeUnixNano)
+	}
+	if len(first.TraceId) != 16 || first.TraceId[0] != 0x4b || len(first.SpanId) != 8 || first.Flags != 1 {
+		t.Errorf("unexpected trace context: %x %x %d", first.TraceId, first.SpanId, first.Flags)
+	}
+	if len(first.Attributes) != 2 || first.Attributes[0].Value.GetIntValue() != 200 ||
+		first.Attributes[1].Value.GetKvlistValue().Values[0].Value.GetStringValue() != "bob" {
+		t.Errorf("unexpected attributes: %v", first.Attributes)
+	}
+	if scopes[1] != "db" || records[1].Attributes[0].Value.GetStringValue() != "timeout" {
+		t.Errorf("unexpected error record: %s %v", scopes[1], records[1].Attributes)
+	}
+}
+
+func TestOTLP_JSON(t *testing.T) {
+	collector := newFakeCollector(t)
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint: collector.URL + "/v1/logs",
+		Encoding: EncodingJSON,
+	})
+
+	err := logger.Log(&multilog.Entry{Time: time.Now(), Level: multilog.ERROR, Group: "api", Message: "failed", Fields: multilog.Fields{
+		multilog.String("trace_id", "4bf92f3577b34da6a3ce929d0e0e4736"),
+		multilog.String("span_id", "00f067aa0ba902b7"),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+
+	var request struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				Scope      struct{ Name string }
+				LogRecords []struct {
+					SeverityNumber int
+					TraceID        string `json:"traceId"`
+					SpanID         string `json:"spanId"`
+				}
+			}
+		}
+	}
+	if len(collector.bodies) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(collector.bodies))
+	}
+	if err := json.Unmarshal(collector.bodies[0], &request); err != nil {
+		t.Fatalf("error decoding request %s: %s", collector.bodies[0], err)
+	}
+	scope := request.ResourceLogs[0].ScopeLogs[0]
+	record := scope.LogRecords[0]
+	if scope.Scope.Name != "api" || record.SeverityNumber != 17 {
+		t.Errorf("unexpected scope or severity: %s %d", scope.Scope.Name, record.SeverityNumber)
+	}
+	if record.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || record.SpanID != "00f067aa0ba902b7" {
+		t.Errorf("expected hex trace and span IDs, got %s %s", record.TraceID, record.SpanID)
+	}
+}
+
+func TestOTLP_Errors(t *testing.T) {
+	collector := newFakeCollector(t)
+	collector.rejected = 1
+
+	var mu sync.Mutex
+	var background []error
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:      collector.URL + "/v1/logs",
+		BatchSize:     2,
+		MaxBufferSize: 1,
+		FlushInterval: time.Hour,
+		ErrorHandler: func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			background = append(background, err)
+		},
+	})
+
+	entry := &multilog.Entry{Time: time.Now(), Level: multilog.INFO, Message: "hello"}
+	if err := logger.Log(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(entry); err == nil {
+		t.Error("expected an error logging with a full buffer")
+	}
+
+	var partial *PartialSuccessError
+	if err := logger.Flush(context.Background()); !errors.As(err, &partial) || partial.Rejected != 1 {
+		t.Errorf("expected a partial success error, got %v", err)
+	}
+
+	if err := logger.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Log(entry); err == nil {
+		t.Error("expected an error logging after Close")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(background) != 0 {
+		t.Errorf("expected no background errors, got %v", background)
+	}
+}
+
+func TestOTLP_Retry(t *testing.T) {
+	collector := newFakeCollector(t)
+	collector.failures = 2
+
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:      collector.URL + "/v1/logs",
+		FlushInterval: time.Hour,
+		RetryBackoff:  time.Millisecond,
+		ErrorHandler:  func(err error) { t.Errorf("unexpected error: %s", err) },
+	})
+
+	if err := logger.Log(&multilog.Entry{Time: time.Now(), Level: multilog.INFO, Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if collector.attempts != 3 || len(collector.requests) != 1 {
+		t.Errorf("got %d attempts and %d exported requests, want 3 and 1", collector.attempts, len(collector.requests))
+	}
+}
+
+func TestOTLP_RetryContext(t *testing.T) {
+	collector := newFakeCollector(t)
+	collector.failures = 1
+	collector.after = "3600"
+
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:      collector.URL + "/v1/logs",
+		FlushInterval: time.Hour,
+		ErrorHandler:  func(err error) {},
+	})
+	if err := logger.Log(&multilog.Entry{Time: time.Now(), Level: multilog.INFO, Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The Retry-After of an hour is cut short by the context, and the record
+	// stays buffered for the next flush.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := logger.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flush returned after %s", elapsed)
+	}
+
+	if err := logger.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.requests) != 1 {
+		t.Errorf("got %d exported requests, want 1", len(collector.requests))
+	}
+}
+
+func TestOTLP_FlushDeadline(t *testing.T) {
+	collector := newFakeCollector(t)
+	collector.failures = 1000
+
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:        collector.URL + "/v1/logs",
+		FlushInterval:   10 * time.Millisecond,
+		MaxRetries:      1000,
+		RetryBackoff:    10 * time.Second,
+		RetryMaxBackoff: 10 * time.Second,
+		ErrorHandler:    func(err error) {},
+	})
+	if err := logger.Log(&multilog.Entry{Time: time.Now(), Level: multilog.INFO, Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the worker to back off with the record, holding the export.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		collector.mu.Lock()
+		attempts := collector.attempts
+		collector.mu.Unlock()
+		if attempts > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the worker did not export the record")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := logger.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("flush returned after %s, past its deadline", elapsed)
+	}
+
+	// Let the Close of the cleanup export the record.
+	collector.mu.Lock()
+	collector.failures = 0
+	collector.mu.Unlock()
+}
+
+func TestOTLP_RetryAfterCap(t *testing.T) {
+	collector := newFakeCollector(t)
+	collector.failures = 1
+	collector.after = "3600"
+
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:        collector.URL + "/v1/logs",
+		FlushInterval:   time.Hour,
+		RetryMaxBackoff: 10 * time.Millisecond,
+		ErrorHandler:    func(err error) {},
+	})
+	if err := logger.Log(&multilog.Entry{Time: time.Now(), Level: multilog.INFO, Message: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := logger.Flush(ctx); err != nil {
+		t.Fatalf("the Retry-After of an hour was not capped: %v", err)
+	}
+}
+
+func TestOTLP_FlushUnderLoad(t *testing.T) {
+	collector := newFakeCollector(t)
+	logger := setup(t, &NewOTLPLoggerArgs{
+		Endpoint:      collector.URL + "/v1/logs",
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+		ErrorHandler:  func(err error) {},
+	})
+
+	entry := &multilog.Entry{Time: time.Now(), Level: multilog.INFO, Message: "hello"}
+	logger.Log(entry)
+	logger.Log(entry)
+
+	// Every export logs another record, so the buffer never empties.
+	collector.mu.Lock()
+	collector.onExport = func() { logger.Log(entry) }
+	collector.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() { done <- logger.Flush(context.Background()) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("flush did not return while records were logged")
+	}
+
+	collector.mu.Lock()
+	collector.onExport = nil
+	collector.mu.Unlock()
+}
+
+func TestSeverity(t *testing.T) {
+	for _, tt := range []struct {
+		level  multilog.LogLevel
+		number logspb.SeverityNumber
+		text   string
+	}{
+		{multilog.TRACE, logspb.SeverityNumber_SEVERITY_NUMBER_TRACE, "TRACE"},
+		{multilog.INFO, logspb.SeverityNumber_SEVERITY_NUMBER_INFO, "INFO"},
+		{multilog.INFO + 2, logspb.SeverityNumber_SEVERITY_NUMBER_INFO3, "INFO+2"},
+		{multilog.FATAL, logspb.SeverityNumber_SEVERITY_NUMBER_FATAL, "FATAL"},
+		{multilog.FATAL + 10, logspb.SeverityNumber_SEVERITY_NUMBER_FATAL4, "FATAL+10"},
+		{multilog.TRACE - 1, logspb.SeverityNumber_SEVERITY_NUMBER_TRACE, "TRACE-1"},
+	} {
+		number, text := severity(tt.level)
+		if number != tt.number || text != tt.text {
+			t.Errorf("severity(%d) = %v %q, want %v %q", tt.level, number, text, tt.number, tt.text)
+		}
+	}
+}
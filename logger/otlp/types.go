@@ -0,0 +1,72 @@
+package otlp
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/mateothegreat/multilog"
+)
+
+// Encoding is the encoding of the OTLP/HTTP export requests.
+type Encoding string
+
+const (
+	// EncodingProtobuf encodes the requests as binary protobuf (application/x-protobuf).
+	EncodingProtobuf Encoding = "protobuf"
+	// EncodingJSON encodes the requests as JSON (application/json).
+	EncodingJSON Encoding = "json"
+)
+
+// NewOTLPLoggerArgs are the arguments to create a new OTLP logger.
+type NewOTLPLoggerArgs struct {
+	// Level is the base of the levels of the returned CustomLogger, which
+	// group patterns set on its Levels can override in either direction.
+	Level multilog.LogLevel
+	// Endpoint is the URL of the collector's OTLP/HTTP logs endpoint. Defaults to DefaultEndpoint.
+	Endpoint string
+	// Encoding is the encoding of the export requests. Defaults to EncodingProtobuf.
+	Encoding Encoding
+	// Headers are added to every export request, for example to authenticate with the collector.
+	Headers map[string]string
+	// Client is the HTTP client used to send the export requests. Defaults to a client
+	// with a timeout of DefaultTimeout.
+	Client *http.Client
+	// ServiceName is set as the service.name attribute of the resource the logs belong to.
+	ServiceName string
+	// Resource are additional attributes of the resource the logs belong to, such as
+	// the deployment environment or the host name.
+	Resource []multilog.Field
+	// FilterDropPatterns is a slice of regex patterns to filter out log messages.
+	FilterDropPatterns []*string
+	// BatchSize is the number of log records sent per export request. Defaults to DefaultBatchSize.
+	BatchSize int
+	// MaxBufferSize is the number of log records that can be buffered while waiting to be
+	// exported. Log records logged while the buffer is full are dropped and reported as an
+	// error. Defaults to DefaultMaxBufferSize.
+	MaxBufferSize int
+	// FlushInterval is the interval at which buffered log records are exported, regardless
+	// of the batch size. Defaults to DefaultFlushInterval.
+	FlushInterval time.Duration
+	// MaxRetries is the number of times an export request that failed with a 429, 502, 503
+	// or 504 status, or because the collector was unreachable, is retried. Defaults to
+	// DefaultMaxRetries, set to a negative value to disable retries.
+	MaxRetries int
+	// RetryBackoff is the base delay before the first retry. The delay doubles with each
+	// retry and is jittered, unless the collector asks for a delay with a Retry-After
+	// header. Defaults to DefaultRetryBackoff.
+	RetryBackoff time.Duration
+	// RetryMaxBackoff caps the delay between retries, including the delay asked for with
+	// a Retry-After header. Defaults to DefaultRetryMaxBackoff.
+	RetryMaxBackoff time.Duration
+	// ErrorHandler is called when an export request sent in the background fails or some
+	// of its log records are rejected by the collector. Defaults to writing the error to os.Stderr.
+	ErrorHandler func(err error)
+}
+
+// OTLPLogger is the logger that exports logs to an OpenTelemetry collector over OTLP/HTTP.
+type OTLPLogger struct {
+	args           *NewOTLPLoggerArgs
+	filterPatterns []*regexp.Regexp
+	exporter       *exporter
+}
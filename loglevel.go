@@ -0,0 +1,227 @@
+package multilog
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// levelNames are the names of the built-in and registered log levels.
+type levelNames struct {
+	names  map[LogLevel]string // names are the names keyed by level.
+	levels map[string]LogLevel // levels are the levels keyed by upper case name.
+}
+
+var (
+	levelsMu      sync.Mutex                 // levelsMu serializes RegisterLevel.
+	levelRegistry atomic.Pointer[levelNames] // levelRegistry holds the current, immutable names.
+)
+
+func init() {
+	names := &levelNames{
+		names:  make(map[LogLevel]string),
+		levels: make(map[string]LogLevel),
+	}
+	for level, name := range map[LogLevel]string{
+		TRACE: "TRACE",
+		DEBUG: "DEBUG",
+		INFO:  "INFO",
+		WARN:  "WARN",
+		ERROR: "ERROR",
+		FATAL: "FATAL",
+	} {
+		names.names[level] = name
+		names.levels[name] = level
+	}
+	levelRegistry.Store(names)
+}
+
+// RegisterLevel registers a custom log level, such as NOTICE between INFO and
+// WARN, so that it is named by String and accepted by ParseLevel. Messages are
+// logged at a custom level with the Log functions, and the console colors them
+// with the color its theme has for their name, if any, or else like the
+// built-in level below them.
+//
+// Registering the same name for the same level again does nothing.
+//
+// Arguments:
+//   - level: The value of the level, such as INFO + 2.
+//   - name: The name of the level, such as "NOTICE".
+//
+// Returns:
+//   - `error` if the name is empty or holds spaces, or if the level or the
+//     name is already registered otherwise.
+//   - `nil` if the level was registered.
+func RegisterLevel(level LogLevel, name string) error {
+	if name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("invalid log level name %q", name)
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	current := levelRegistry.Load()
+	key := strings.ToUpper(name)
+	if existing, ok := current.levels[key]; ok {
+		if existing == level && current.names[level] == name {
+			return nil
+		}
+		return fmt.Errorf("log level name %s is already registered for level %d", name, existing)
+	}
+	if existing, ok := current.names[level]; ok {
+		return fmt.Errorf("log level %d is already registered as %s", level, existing)
+	}
+
+	names := &levelNames{
+		names:  make(map[LogLevel]string, len(current.names)+1),
+		levels: make(map[string]LogLevel, len(current.levels)+1),
+	}
+	for l, n := range current.names {
+		names.names[l] = n
+	}
+	for n, l := range current.levels {
+		names.levels[n] = l
+	}
+	names.names[level] = name
+	names.levels[key] = level
+	levelRegistry.Store(names)
+
+	return nil
+}
+
+// legacyLevels are the built-in levels indexed by the integer values they had
+// before they were spaced four apart, which ParseLevel names in its error.
+var legacyLevels = [...]LogLevel{TRACE, DEBUG, INFO, WARN, ERROR, FATAL}
+
+// ParseLevel parses a log level from its name, case insensitively, or from a
+// name followed by an offset such as "INFO+2", as returned by String for the
+// levels without a name.
+//
+// Bare integers are rejected rather than read as level values, since the
+// integers 0 to 5 stood for TRACE to FATAL before the levels were spaced four
+// apart. The error names the level such an integer stood for.
+//
+// Arguments:
+//   - s: The text to parse.
+//
+// Returns:
+//   - LogLevel: The parsed log level.
+//   - `error` if the text is not a known name or an offset from one.
+func ParseLevel(s string) (LogLevel, error) {
+	names := levelRegistry.Load()
+	name := strings.ToUpper(strings.TrimSpace(s))
+
+	if level, ok := names.levels[name]; ok {
+		return level, nil
+	}
+	if i := strings.LastIndexAny(name, "+-"); i > 0 {
+		if level, ok := names.levels[name[:i]]; ok {
+			if offset, err := strconv.Atoi(name[i:]); err == nil {
+				return level + LogLevel(offset), nil
+			}
+		}
+	}
+	if value, err := strconv.Atoi(name); err == nil {
+		if value >= 0 && value < len(legacyLevels) {
+			return 0, fmt.Errorf("log level %d must be given by name, use %s if it was written for the integer levels of earlier versions", value, legacyLevels[value])
+		}
+		return 0, fmt.Errorf("log level %d must be given by name, such as %s", value, INFO)
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// LevelFromSlog converts a slog level to a log level. The slog levels map to
+// the log levels of the same name, and the levels between them keep their
+// distance, such that slog.LevelDebug-4 is TRACE and slog.LevelError+4 is FATAL.
+func LevelFromSlog(level slog.Level) LogLevel {
+	return LogLevel(level) + INFO
+}
+
+// SlogLevel converts the log level to a slog level, as the reverse of LevelFromSlog.
+func (l LogLevel) SlogLevel() slog.Level {
+	return slog.Level(l - INFO)
+}
+
+// String returns the name of the log level. A level without a name is named
+// after the closest named level below it, with the distance appended, such as
+// "INFO+2".
+func (l LogLevel) String() string {
+	names := levelRegistry.Load()
+	if name, ok := names.names[l]; ok {
+		return name
+	}
+
+	base, found := TRACE, false
+	for named := range names.names {
+		if named <= l && (!found || named > base) {
+			base, found = named, true
+		}
+	}
+	return fmt.Sprintf("%s%+d", names.names[base], l-base)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the name of the level.
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the level as
+// described for ParseLevel.
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the level as its name.
+func (l LogLevel) MarshalJSON() ([]byte, error) {
+	return appendJSONString(nil, l.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting the name of the level
+// as described for ParseLevel. A number is rejected with an error naming the
+// level it stood for.
+func (l *LogLevel) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+	} else {
+		text = string(data)
+	}
+
+	return l.UnmarshalText([]byte(text))
+}
+
+// standard returns the closest built-in level at or below the level, or TRACE
+// for the levels below it.
+func (l LogLevel) standard() LogLevel {
+	switch {
+	case l >= FATAL:
+		return FATAL
+	case l >= ERROR:
+		return ERROR
+	case l >= WARN:
+		return WARN
+	case l >= INFO:
+		return INFO
+	case l >= DEBUG:
+		return DEBUG
+	default:
+		return TRACE
+	}
+}
@@ -0,0 +1,139 @@
+package multilog
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogLevel_String(t *testing.T) {
+	for level, want := range map[LogLevel]string{
+		TRACE:     "TRACE",
+		DEBUG:     "DEBUG",
+		INFO:      "INFO",
+		WARN:      "WARN",
+		ERROR:     "ERROR",
+		FATAL:     "FATAL",
+		INFO + 1:  "INFO+1",
+		FATAL + 8: "FATAL+8",
+		TRACE - 2: "TRACE-2",
+	} {
+		if got := level.String(); got != want {
+			t.Errorf("LogLevel(%d).String() = %q, want %q", int(level), got, want)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for text, want := range map[string]LogLevel{
+		"trace":   TRACE,
+		" Warn ":  WARN,
+		"INFO+1":  INFO + 1,
+		"debug-3": DEBUG - 3,
+	} {
+		got, err := ParseLevel(text)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %d, %v, want %d", text, got, err, want)
+		}
+	}
+
+	for _, text := range []string{"", "LOUD", "INFO+", "INFO+x", "0", "2", "-1", "6", "20"} {
+		if _, err := ParseLevel(text); err == nil {
+			t.Errorf("expected an error parsing %q", text)
+		}
+	}
+
+	if _, err := ParseLevel("2"); err == nil || !strings.Contains(err.Error(), "use INFO") {
+		t.Errorf("expected an error naming INFO, got %v", err)
+	}
+}
+
+func TestLogLevel_JSON(t *testing.T) {
+	b, err := json.Marshal(map[string]LogLevel{"level": WARN})
+	if err != nil || string(b) != `{"level":"WARN"}` {
+		t.Errorf("unexpected JSON %s: %v", b, err)
+	}
+
+	var got struct{ A, B, C LogLevel }
+	if err := json.Unmarshal([]byte(`{"A": "error", "B": "debug", "C": null}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.A != ERROR || got.B != DEBUG || got.C != TRACE {
+		t.Errorf("unexpected levels: %+v", got)
+	}
+
+	for _, data := range []string{`{"A": "LOUD"}`, `{"A": 1}`, `{"A": 16}`} {
+		if err := json.Unmarshal([]byte(data), &got); err == nil {
+			t.Errorf("expected an error for %s", data)
+		}
+	}
+}
+
+func TestLogLevel_Slog(t *testing.T) {
+	for level, want := range map[LogLevel]slog.Level{
+		TRACE: slog.LevelDebug - 4,
+		DEBUG: slog.LevelDebug,
+		INFO:  slog.LevelInfo,
+		WARN:  slog.LevelWarn,
+		ERROR: slog.LevelError,
+		FATAL: slog.LevelError + 4,
+	} {
+		if got := level.SlogLevel(); got != want {
+			t.Errorf("%s.SlogLevel() = %s, want %s", level, got, want)
+		}
+		if got := LevelFromSlog(want); got != level {
+			t.Errorf("LevelFromSlog(%s) = %s, want %s", want, got, level)
+		}
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	notice := INFO + 2
+	if err := RegisterLevel(notice, "NOTICE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterLevel(notice, "NOTICE"); err != nil {
+		t.Errorf("registering the same level again should succeed: %v", err)
+	}
+
+	for _, tt := range []struct {
+		level LogLevel
+		name  string
+	}{
+		{notice, "ANNOUNCE"},
+		{INFO + 3, "notice"},
+		{INFO, "INFORMATION"},
+		{INFO + 3, ""},
+		{INFO + 3, "TWO WORDS"},
+	} {
+		if err := RegisterLevel(tt.level, tt.name); err == nil {
+			t.Errorf("expected an error registering %q as %d", tt.name, tt.level)
+		}
+	}
+
+	if notice.String() != "NOTICE" || (notice+1).String() != "NOTICE+1" {
+		t.Errorf("unexpected names %s and %s", notice, notice+1)
+	}
+	if level, err := ParseLevel("notice"); err != nil || level != notice {
+		t.Errorf("ParseLevel(notice) = %d, %v", level, err)
+	}
+
+	var got []*Entry
+	m := NewMultilog(&NewMultilogArgs{Level: INFO})
+	m.RegisterLogger("memory", &CustomLogger{Log: func(entry *Entry) error {
+		got = append(got, entry)
+		return nil
+	}})
+	m.Log(notice, "audit", "user created")
+	m.WithGroup("audit").Log(DEBUG, "", "dropped")
+
+	if len(got) != 1 || got[0].Level != notice {
+		t.Fatalf("unexpected entries: %v", got)
+	}
+	b, _ := JSONEncoder{}.Encode(nil, got[0])
+	var decoded map[string]any
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded["level"] != "NOTICE" {
+		t.Errorf("unexpected JSON %s: %v", b, err)
+	}
+}
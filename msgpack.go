@@ -0,0 +1,159 @@
+package multilog
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// MessagePackEncoder encodes entries as MessagePack maps holding the time,
+// level, group, message and data, the fields of the entry as a nested map.
+// https://github.com/msgpack/msgpack/blob/master/spec.md
+//
+// MessagePack values are self-delimiting, so no separator is added between
+// entries. Times use the timestamp extension type, durations are encoded as
+// nanoseconds, errors as their message and values without a MessagePack
+// equivalent as their text representation.
+type MessagePackEncoder struct{}
+
+// Encode implements Encoder.
+func (MessagePackEncoder) Encode(dst []byte, entry *Entry) ([]byte, error) {
+	dst = appendMsgpackMapHeader(dst, 5)
+	dst = appendMsgpackString(dst, "time")
+	dst = appendMsgpackTime(dst, entry.Time)
+	dst = appendMsgpackString(dst, "level")
+	dst = appendMsgpackString(dst, entry.Level.String())
+	dst = appendMsgpackString(dst, "group")
+	dst = appendMsgpackString(dst, entry.Group)
+	dst = appendMsgpackString(dst, "message")
+	dst = appendMsgpackString(dst, entry.Message)
+	dst = appendMsgpackString(dst, "data")
+	dst = appendMsgpackFields(dst, entry.Fields)
+
+	return dst, nil
+}
+
+// appendMsgpackFields appends the fields as a map.
+func appendMsgpackFields(dst []byte, fields Fields) []byte {
+	dst = appendMsgpackMapHeader(dst, len(fields))
+	for _, f := range fields {
+		dst = appendMsgpackString(dst, f.Key)
+
+		switch f.Type {
+		case StringType:
+			dst = appendMsgpackString(dst, f.String)
+		case IntType, DurationType:
+			dst = appendMsgpackInt(dst, f.Integer)
+		case UintType:
+			dst = appendMsgpackUint(dst, uint64(f.Integer))
+		case FloatType:
+			dst = append(dst, 0xcb)
+			dst = binary.BigEndian.AppendUint64(dst, uint64(f.Integer))
+		case BoolType:
+			if f.Integer == 1 {
+				dst = append(dst, 0xc3)
+			} else {
+				dst = append(dst, 0xc2)
+			}
+		case TimeType:
+			dst = appendMsgpackTime(dst, f.time())
+		case ObjectType:
+			dst = appendMsgpackFields(dst, f.Interface.(Fields))
+		default:
+			switch v := f.Interface.(type) {
+			case nil:
+				dst = append(dst, 0xc0)
+			case []byte:
+				dst = appendMsgpackBinary(dst, v)
+			default:
+				dst = appendMsgpackString(dst, f.Text())
+			}
+		}
+	}
+
+	return dst
+}
+
+// appendMsgpackMapHeader appends the header of a map with n entries.
+func appendMsgpackMapHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(dst, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, 0xdf), uint32(n))
+	}
+}
+
+// appendMsgpackString appends a string.
+func appendMsgpackString(dst []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+
+	return append(dst, s...)
+}
+
+// appendMsgpackBinary appends a byte slice.
+func appendMsgpackBinary(dst []byte, b []byte) []byte {
+	switch n := len(b); {
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xc5), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xc6), uint32(n))
+	}
+
+	return append(dst, b...)
+}
+
+// appendMsgpackInt appends a signed integer in its most compact form.
+func appendMsgpackInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(dst, uint64(i))
+	case i >= -32:
+		return append(dst, byte(i))
+	case i >= math.MinInt8:
+		return append(dst, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(i))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(i))
+	}
+}
+
+// appendMsgpackUint appends an unsigned integer in its most compact form.
+func appendMsgpackUint(dst []byte, u uint64) []byte {
+	switch {
+	case u <= 0x7f:
+		return append(dst, byte(u))
+	case u <= math.MaxUint8:
+		return append(dst, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(u))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), u)
+	}
+}
+
+// appendMsgpackTime appends a time using the timestamp 96 extension type,
+// which holds the nanoseconds and the seconds since the Unix epoch.
+func appendMsgpackTime(dst []byte, t time.Time) []byte {
+	dst = append(dst, 0xc7, 12, 0xff)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(t.Nanosecond()))
+	return binary.BigEndian.AppendUint64(dst, uint64(t.Unix()))
+}
@@ -0,0 +1,577 @@
+package multilog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// NewMultilogArgs are the arguments for the NewMultilog function.
+type NewMultilogArgs struct {
+	// Level is the minimum log level dispatched to the registered loggers.
+	// It is ignored when Levels is set.
+	Level LogLevel
+	// Levels are the minimum log levels dispatched to the registered loggers
+	// for each group. Defaults to NewLevels(Level), and can be changed at
+	// runtime through the Levels method.
+	Levels *Levels
+	// Async enables asynchronous dispatch. Each registered logger gets its own
+	// bounded queue and worker goroutine, and log calls return as soon as the
+	// message has been enqueued.
+	Async bool
+	// QueueSize is the capacity of each logger's queue when Async is enabled.
+	// Defaults to DefaultQueueSize.
+	QueueSize int
+	// Overflow is the policy applied when a logger's queue is full.
+	// Defaults to OverflowBlock.
+	Overflow OverflowPolicy
+	// FatalTimeout is how long Fatal waits for the loggers to be flushed and
+	// closed before exiting the process. Defaults to DefaultFatalTimeout.
+	FatalTimeout time.Duration
+	// ErrorHandler is called when a logger fails to write a message or to
+	// shut down in the background. Defaults to writing the error to os.Stderr.
+	ErrorHandler ErrorHandler
+	// ContextExtractors are the functions that extract fields from the context
+	// passed to the *Ctx log functions. More can be added with AddContextExtractor.
+	ContextExtractors []ContextExtractor
+	// DisableTraceFields stops the trace_id, span_id and trace_flags of the
+	// OpenTelemetry span carried by the context from being added to every entry.
+	DisableTraceFields bool
+	// SpanEvents records every WARN or higher entry logged with a context
+	// carrying a recording OpenTelemetry span as an event on that span.
+	SpanEvents bool
+	// Caller adds the caller field, holding the file and line the message was
+	// logged from, and the function field, holding the function, to every entry.
+	Caller bool
+	// CallerSkip is the number of frames skipped past the first one outside
+	// this package to find the caller, for wrappers of the log functions.
+	CallerSkip int
+	// StackLevel is the minimum level of the entries to which the stack field,
+	// holding the stack trace from the caller, is added, such as ERROR.
+	// Stack traces are not captured when nil.
+	StackLevel *LogLevel
+}
+
+// DefaultFatalTimeout is the FatalTimeout used when NewMultilogArgs.FatalTimeout is not set.
+const DefaultFatalTimeout = 5 * time.Second
+
+// Multilog is a dispatcher that fans out log messages to its own set of
+// registered loggers.
+//
+// Each Multilog is independent of every other instance, so multiple pipelines
+// can be configured side by side in the same process. All methods are safe for
+// concurrent use.
+type Multilog struct {
+	args       *NewMultilogArgs    // args are the arguments for the NewMultilog function.
+	mu         sync.RWMutex        // mu guards loggers and extractors.
+	loggers    map[LogMethod]*sink // loggers are the registered loggers keyed by log method.
+	extractors []ContextExtractor  // extractors are the registered context extractors, in order.
+	levels     *Levels             // levels are the minimum log levels for each group.
+}
+
+// sink is a registered logger together with its queue when dispatch is asynchronous.
+type sink struct {
+	method  LogMethod     // method is the log method the logger is registered for.
+	logger  *CustomLogger // logger is the registered logger.
+	queue   *queue        // queue is the logger's queue, nil when dispatch is synchronous.
+	levels  *Levels       // levels are the logger's minimum log levels for each group.
+	onError ErrorHandler  // onError handles the errors returned by the logger.
+}
+
+// NewMultilog creates a new Multilog dispatcher with no registered loggers.
+//
+// Arguments:
+//   - args <*NewMultilogArgs>: The arguments to create a new dispatcher, nil uses the defaults.
+//
+// Returns:
+//   - *Multilog: The new dispatcher.
+func NewMultilog(args *NewMultilogArgs) *Multilog {
+	if args == nil {
+		args = &NewMultilogArgs{}
+	}
+	if args.ErrorHandler == nil {
+		args.ErrorHandler = stderrErrorHandler
+	}
+	if args.Levels == nil {
+		args.Levels = NewLevels(args.Level)
+	}
+
+	var extractors []ContextExtractor
+	if !args.DisableTraceFields {
+		extractors = append(extractors, TraceFields)
+	}
+	extractors = append(extractors, args.ContextExtractors...)
+
+	return &Multilog{
+		args:       args,
+		loggers:    make(map[LogMethod]*sink),
+		extractors: extractors,
+		levels:     args.Levels,
+	}
+}
+
+// Levels returns the minimum log levels dispatched to the registered loggers,
+// which can be changed while messages are logged.
+//
+// Returns:
+//   - *Levels: The levels of the dispatcher.
+func (m *Multilog) Levels() *Levels {
+	return m.levels
+}
+
+// NewLogger creates a new logger for the given log method and registers it.
+//
+// Arguments:
+//   - t: The log method to create a logger for.
+//
+// Returns:
+//   - A new, empty logger registered for the given log method. Any logger
+//     previously registered for the log method is replaced.
+func (m *Multilog) NewLogger(t LogMethod) *CustomLogger {
+	logger := &CustomLogger{}
+	m.swap(t, logger)
+
+	return logger
+}
+
+// RegisterLogger registers a custom logger for a given log method.
+//
+// The logger's Setup function is called before the logger is registered.
+//
+// Arguments:
+//   - t: The log method to register a logger for.
+//   - logger: The custom logger to register.
+//
+// Returns:
+//   - `error` if the logger for the given log method is already registered or
+//     its Setup function returns an error.
+//   - `nil` if the logger for the given log method is successfully registered.
+func (m *Multilog) RegisterLogger(t LogMethod, logger *CustomLogger) error {
+	if m.registered(t) {
+		return fmt.Errorf("logger for log method %s already registered", t)
+	}
+
+	// Setup is called without holding the lock so that a slow setup (such as
+	// connecting to a remote cluster) does not block in-flight log calls.
+	if err := setup(t, logger); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.loggers[t]; exists {
+		return fmt.Errorf("logger for log method %s already registered", t)
+	}
+	m.loggers[t] = m.newSink(t, logger)
+
+	return nil
+}
+
+// UnregisterLogger removes the logger registered for a given log method.
+//
+// Log calls that are already in flight complete against the removed logger,
+// and when dispatch is asynchronous its queued messages are still delivered.
+// The removed logger is then flushed and closed in the background.
+//
+// Arguments:
+//   - t: The log method to unregister.
+//
+// Returns:
+//   - `error` if no logger is registered for the given log method.
+//   - `nil` if the logger was successfully unregistered.
+func (m *Multilog) UnregisterLogger(t LogMethod) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, exists := m.loggers[t]
+	if !exists {
+		return fmt.Errorf("logger for log method %s is not registered", t)
+	}
+	delete(m.loggers, t)
+	go s.shutdownInBackground()
+
+	return nil
+}
+
+// ReplaceLogger registers a custom logger for a given log method, replacing
+// any logger that is already registered for it.
+//
+// The new logger's Setup function is called before it is swapped in, and log
+// calls that are already in flight complete against the previous logger. The
+// previous logger is then flushed and closed in the background.
+//
+// Arguments:
+//   - t: The log method to register a logger for.
+//   - logger: The custom logger to register.
+//
+// Returns:
+//   - `error` if the logger's Setup function returns an error, in which case
+//     the previous logger stays registered.
+//   - `nil` if the logger for the given log method is successfully replaced.
+func (m *Multilog) ReplaceLogger(t LogMethod, logger *CustomLogger) error {
+	if err := setup(t, logger); err != nil {
+		return err
+	}
+
+	m.swap(t, logger)
+	return nil
+}
+
+// Flush waits for every registered logger's queued messages to be delivered
+// and then calls each logger's Flush function.
+//
+// Arguments:
+//   - ctx: The context that bounds how long Flush waits.
+//
+// Returns:
+//   - `error` joining the errors of every logger that failed to flush in time.
+//   - `nil` if every logger was flushed.
+func (m *Multilog) Flush(ctx context.Context) error {
+	sinks := m.snapshot()
+	errs := make([]error, len(sinks))
+
+	wg := sync.WaitGroup{}
+	for i, s := range sinks {
+		wg.Add(1)
+		go func(i int, s *sink) {
+			defer wg.Done()
+			errs[i] = s.flush(ctx)
+		}(i, s)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Shutdown unregisters every logger, waits for their queued messages to be
+// delivered and then calls each logger's Flush and Close functions.
+//
+// Messages logged after Shutdown has been called are not delivered to the
+// loggers that were removed.
+//
+// Arguments:
+//   - ctx: The context that bounds how long Shutdown waits.
+//
+// Returns:
+//   - `error` joining the errors of every logger that failed to shut down in time.
+//   - `nil` if every logger was shut down.
+func (m *Multilog) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	sinks := make([]*sink, 0, len(m.loggers))
+	for _, s := range m.loggers {
+		sinks = append(sinks, s)
+	}
+	m.loggers = make(map[LogMethod]*sink)
+	m.mu.Unlock()
+
+	errs := make([]error, len(sinks))
+
+	wg := sync.WaitGroup{}
+	for i, s := range sinks {
+		wg.Add(1)
+		go func(i int, s *sink) {
+			defer wg.Done()
+			errs[i] = s.shutdown(ctx)
+		}(i, s)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Dropped returns the number of messages dropped by the overflow policy for
+// each registered logger. It is always empty when dispatch is synchronous.
+//
+// Returns:
+//   - map[LogMethod]uint64: The dropped message counts keyed by log method.
+func (m *Multilog) Dropped() map[LogMethod]uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	dropped := make(map[LogMethod]uint64)
+	for t, s := range m.loggers {
+		if s.queue != nil {
+			dropped[t] = s.queue.dropped.Load()
+		}
+	}
+
+	return dropped
+}
+
+// Registered returns the log methods that currently have a registered logger.
+//
+// Returns:
+//   - []LogMethod: The registered log methods in sorted order.
+func (m *Multilog) Registered() []LogMethod {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	methods := make([]LogMethod, 0, len(m.loggers))
+	for t := range m.loggers {
+		methods = append(methods, t)
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i] < methods[j] })
+
+	return methods
+}
+
+// registered reports whether a logger is registered for the given log method.
+func (m *Multilog) registered(t LogMethod) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, exists := m.loggers[t]
+	return exists
+}
+
+// setup calls the logger's Setup function, if any.
+func setup(t LogMethod, logger *CustomLogger) error {
+	if logger.Setup == nil {
+		return nil
+	}
+
+	if err := logger.Setup(); err != nil {
+		return fmt.Errorf("error setting up logger for log method %s: %w", t, err)
+	}
+
+	return nil
+}
+
+// newSink wraps a logger for registration, starting its queue when dispatch
+// is asynchronous and giving it levels that enable every message if it has none.
+func (m *Multilog) newSink(t LogMethod, logger *CustomLogger) *sink {
+	if logger.Levels == nil {
+		logger.Levels = NewLevels(TRACE)
+	}
+
+	s := &sink{
+		method:  t,
+		logger:  logger,
+		levels:  logger.Levels,
+		onError: m.args.ErrorHandler,
+	}
+	if m.args.Async {
+		s.queue = newQueue(s.deliver, m.args.QueueSize, m.args.Overflow)
+	}
+
+	return s
+}
+
+// swap registers a logger for the given log method and shuts down the sink it replaces.
+func (m *Multilog) swap(t LogMethod, logger *CustomLogger) {
+	s := m.newSink(t, logger)
+
+	m.mu.Lock()
+	previous := m.loggers[t]
+	m.loggers[t] = s
+	m.mu.Unlock()
+
+	if previous != nil {
+		go previous.shutdownInBackground()
+	}
+}
+
+// snapshot returns the currently registered sinks so that they can be called
+// without holding the lock.
+func (m *Multilog) snapshot() []*sink {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	sinks := make([]*sink, 0, len(m.loggers))
+	for _, s := range m.loggers {
+		sinks = append(sinks, s)
+	}
+
+	return sinks
+}
+
+// deliver writes an entry to the sink's logger and reports any error to the
+// sink's error handler.
+func (s *sink) deliver(entry *Entry) {
+	if s.logger.Log == nil {
+		return // The logger was created with NewLogger but Log was never assigned.
+	}
+
+	if err := s.logger.Log(entry); err != nil {
+		s.onError(s.method, entry, err)
+	}
+}
+
+// shutdownInBackground shuts down a sink that has been removed from the
+// registry and reports any error to the sink's error handler.
+func (s *sink) shutdownInBackground() {
+	if err := s.shutdown(context.Background()); err != nil {
+		s.onError(s.method, nil, err)
+	}
+}
+
+// flush waits for the sink's queue to drain and then flushes its logger.
+func (s *sink) flush(ctx context.Context) error {
+	if s.queue != nil {
+		if err := s.queue.flush(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.logger.Flush != nil {
+		return s.logger.Flush(ctx)
+	}
+
+	return nil
+}
+
+// shutdown closes the sink's queue, waits for its worker to exit and then
+// flushes and closes its logger.
+func (s *sink) shutdown(ctx context.Context) error {
+	if s.queue != nil {
+		s.queue.close()
+		select {
+		case <-s.queue.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var errs []error
+	if s.logger.Flush != nil {
+		errs = append(errs, s.logger.Flush(ctx))
+	}
+	if s.logger.Close != nil {
+		errs = append(errs, s.logger.Close(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Trace logs a trace message to all registered loggers at the TRACE level.
+func (m *Multilog) Trace(group string, message string, fields ...Field) {
+	m.log(context.Background(), TRACE, group, message, fields)
+}
+
+// Debug logs a debug message to all registered loggers at the DEBUG level.
+func (m *Multilog) Debug(group string, message string, fields ...Field) {
+	m.log(context.Background(), DEBUG, group, message, fields)
+}
+
+// Info logs an info message to all registered loggers at the INFO level.
+func (m *Multilog) Info(group string, message string, fields ...Field) {
+	m.log(context.Background(), INFO, group, message, fields)
+}
+
+// Warn logs a warn message to all registered loggers at the WARN level.
+func (m *Multilog) Warn(group string, message string, fields ...Field) {
+	m.log(context.Background(), WARN, group, message, fields)
+}
+
+// Error logs an error message to all registered loggers at the ERROR level.
+func (m *Multilog) Error(group string, message string, fields ...Field) {
+	m.log(context.Background(), ERROR, group, message, fields)
+}
+
+// Fatal logs a fatal message to all registered loggers at the FATAL level,
+// shuts the loggers down so that the message is written out and then exits
+// the process with status code 1.
+func (m *Multilog) Fatal(group string, message string, fields ...Field) {
+	m.log(context.Background(), FATAL, group, message, fields)
+	m.exit()
+}
+
+// Log logs a message to all registered loggers at the given level, which may
+// be a custom level registered with RegisterLevel. Unlike Fatal, it never
+// exits the process.
+func (m *Multilog) Log(level LogLevel, group string, message string, fields ...Field) {
+	m.log(context.Background(), level, group, message, fields)
+}
+
+// exit shuts the loggers down, bounded by the fatal timeout, and then exits
+// the process with status code 1.
+func (m *Multilog) exit() {
+	timeout := m.args.FatalTimeout
+	if timeout <= 0 {
+		timeout = DefaultFatalTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := m.Shutdown(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "multilog: error shutting down loggers: %s\n", err)
+	}
+
+	os.Exit(1)
+}
+
+// log dispatches a message to every registered logger.
+//
+// When dispatch is synchronous each logger is called concurrently and log
+// blocks until all of them have returned. When dispatch is asynchronous the
+// message is enqueued for each logger and log returns immediately, subject to
+// the overflow policy.
+//
+// The fields attached to ctx with NewContext and the fields returned by the
+// context extractors are prepended to the given fields.
+func (m *Multilog) log(ctx context.Context, level LogLevel, group string, message string, fields []Field) {
+	m.logAt(ctx, time.Now(), level, group, message, fields)
+}
+
+// logAt dispatches a message logged at the given time to every registered
+// logger, as described for log.
+func (m *Multilog) logAt(ctx context.Context, t time.Time, level LogLevel, group string, message string, fields []Field) {
+	// Check if the log level is sufficient to dispatch the message.
+	if !m.levels.Enabled(level, group) {
+		return
+	}
+
+	// Fields from the context come first so that the call's own fields follow
+	// the request-scoped ones.
+	if extracted := m.contextFields(ctx); len(extracted) > 0 {
+		fields = append(extracted, fields...)
+	}
+
+	// The caller and stack fields come last. They are added to a copy so that
+	// the caller's slice is never appended to.
+	if captured := m.callerFields(level); len(captured) > 0 {
+		merged := make(Fields, 0, len(fields)+len(captured))
+		merged = append(merged, fields...)
+		fields = append(merged, captured...)
+	}
+
+	entry := &Entry{
+		Time:    t,
+		Level:   level,
+		Group:   group,
+		Message: message,
+		Fields:  fields,
+	}
+
+	if m.args.SpanEvents && level >= WARN {
+		addSpanEvent(ctx, entry)
+	}
+
+	wg := sync.WaitGroup{}
+	for _, s := range m.snapshot() {
+		if !s.levels.Enabled(level, group) {
+			continue
+		}
+		if s.queue != nil {
+			s.queue.enqueue(entry)
+			continue
+		}
+		wg.Add(1)
+		go func(s *sink) {
+			defer wg.Done()
+			s.deliver(entry)
+		}(s)
+	}
+	wg.Wait()
+}
+
+// stderrErrorHandler is the default ErrorHandler, which writes the error to os.Stderr.
+func stderrErrorHandler(method LogMethod, entry *Entry, err error) {
+	fmt.Fprintf(os.Stderr, "multilog: error from logger for log method %s: %s\n", method, err)
+}
@@ -0,0 +1,234 @@
+package multilog
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMultilog_Isolated(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string][]string{}
+
+	record := func(name string) *CustomLogger {
+		return &CustomLogger{
+			Log: func(entry *Entry) error {
+				mu.Lock()
+				defer mu.Unlock()
+				got[name] = append(got[name], entry.Message)
+				return nil
+			},
+		}
+	}
+
+	a := NewMultilog(nil)
+	b := NewMultilog(&NewMultilogArgs{Level: WARN})
+
+	if err := a.RegisterLogger("memory", record("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.RegisterLogger("memory", record("b")); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.RegisterLogger("memory", record("a")); err == nil {
+		t.Fatal("expected an error registering the same log method twice")
+	}
+
+	a.Info("test", "to a")
+	b.Info("test", "dropped by level")
+	b.Error("test", "to b")
+
+	if len(got["a"]) != 1 || got["a"][0] != "to a" {
+		t.Errorf("unexpected messages for a: %v", got["a"])
+	}
+	if len(got["b"]) != 1 || got["b"][0] != "to b" {
+		t.Errorf("unexpected messages for b: %v", got["b"])
+	}
+}
+
+func TestMultilog_ConcurrentRegistry(t *testing.T) {
+	m := NewMultilog(nil)
+	noop := func() *CustomLogger {
+		return &CustomLogger{Log: func(*Entry) error { return nil }}
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.Info("test", "message")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if err := m.ReplaceLogger("memory", noop()); err != nil {
+					t.Error(err)
+				}
+				m.Registered()
+				m.UnregisterLogger("memory")
+			}
+		}()
+	}
+	wg.Wait()
+
+	m.ReplaceLogger("b", noop())
+	m.ReplaceLogger("a", noop())
+	if got := m.Registered(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("unexpected registered log methods: %v", got)
+	}
+	if err := m.UnregisterLogger("missing"); err == nil {
+		t.Error("expected an error unregistering a missing log method")
+	}
+}
+
+func TestMultilog_AsyncOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		overflow OverflowPolicy
+		want     []string
+	}{
+		{OverflowDropNewest, []string{"first", "1", "2"}},
+		{OverflowDropOldest, []string{"first", "3", "4"}},
+	} {
+		t.Run(string(tc.overflow), func(t *testing.T) {
+			started := make(chan struct{})
+			release := make(chan struct{})
+			delivered := make(chan string, 8)
+
+			m := NewMultilog(&NewMultilogArgs{Async: true, QueueSize: 2, Overflow: tc.overflow})
+			m.RegisterLogger("memory", &CustomLogger{
+				Log: func(entry *Entry) error {
+					if entry.Message == "first" {
+						close(started)
+						<-release
+					}
+					delivered <- entry.Message
+					return nil
+				},
+			})
+
+			// Wait for the worker to pick up the first message so that the
+			// remaining messages fill the queue behind it.
+			m.Info("test", "first")
+			<-started
+			for _, message := range []string{"1", "2", "3", "4"} {
+				m.Info("test", message)
+			}
+			close(release)
+
+			for _, want := range tc.want {
+				if got := <-delivered; got != want {
+					t.Errorf("got %q, want %q", got, want)
+				}
+			}
+			if dropped := m.Dropped()["memory"]; dropped != 2 {
+				t.Errorf("got %d dropped messages, want 2", dropped)
+			}
+		})
+	}
+}
+
+func TestMultilog_FlushAndShutdown(t *testing.T) {
+	var delivered, flushed, closed atomic.Int32
+
+	m := NewMultilog(&NewMultilogArgs{Async: true})
+	m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			time.Sleep(time.Millisecond)
+			delivered.Add(1)
+			return nil
+		},
+		Flush: func(ctx context.Context) error {
+			flushed.Add(1)
+			return nil
+		},
+		Close: func(ctx context.Context) error {
+			closed.Add(1)
+			return nil
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		m.Info("test", "message")
+	}
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := delivered.Load(); got != 10 {
+		t.Errorf("got %d delivered messages after Flush, want 10", got)
+	}
+
+	m.Info("test", "message")
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := delivered.Load(); got != 11 {
+		t.Errorf("got %d delivered messages after Shutdown, want 11", got)
+	}
+	if flushed.Load() != 2 || closed.Load() != 1 {
+		t.Errorf("got %d flushes and %d closes, want 2 and 1", flushed.Load(), closed.Load())
+	}
+	if got := m.Registered(); len(got) != 0 {
+		t.Errorf("unexpected registered log methods after Shutdown: %v", got)
+	}
+}
+
+func TestMultilog_FlushTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	m := NewMultilog(&NewMultilogArgs{Async: true})
+	m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			<-release
+			return nil
+		},
+	})
+	m.Info("test", "stuck")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := m.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestMultilog_Errors(t *testing.T) {
+	var handled []error
+
+	m := NewMultilog(&NewMultilogArgs{
+		ErrorHandler: func(method LogMethod, entry *Entry, err error) {
+			if method != "failing" || entry == nil || entry.Message != "message" {
+				t.Errorf("unexpected method %q and entry %+v", method, entry)
+			}
+			handled = append(handled, err)
+		},
+	})
+
+	err := m.RegisterLogger("broken", &CustomLogger{
+		Setup: func() error { return errors.New("connection refused") },
+	})
+	if err == nil {
+		t.Fatal("expected the setup error to be returned")
+	}
+	if got := m.Registered(); len(got) != 0 {
+		t.Errorf("unexpected registered log methods after a failed setup: %v", got)
+	}
+
+	m.RegisterLogger("failing", &CustomLogger{
+		Log: func(entry *Entry) error {
+			return errors.New("write failed")
+		},
+	})
+	m.Error("test", "message")
+
+	if len(handled) != 1 || handled[0].Error() != "write failed" {
+		t.Errorf("unexpected handled errors: %v", handled)
+	}
+}
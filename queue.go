@@ -0,0 +1,179 @@
+package multilog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy is the policy applied when an asynchronous queue is full.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock blocks the caller until there is room in the queue.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropNewest drops the entry being enqueued.
+	OverflowDropNewest OverflowPolicy = "drop_newest"
+	// OverflowDropOldest drops the oldest queued entry to make room for the new one.
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// DefaultQueueSize is the queue size used when NewMultilogArgs.QueueSize is not set.
+const DefaultQueueSize = 1024
+
+// queue is a bounded FIFO of entries consumed by a single worker goroutine
+// that delivers each entry to a logger.
+type queue struct {
+	deliver  func(entry *Entry) // deliver is called by the worker for each entry.
+	size     int                // size is the maximum number of queued entries.
+	overflow OverflowPolicy     // overflow is the policy applied when the queue is full.
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond // notEmpty is signalled when an entry is enqueued or the queue is closed.
+	notFull  *sync.Cond // notFull is signalled when an entry is dequeued or the queue is closed.
+	entries  []*Entry   // entries are the queued entries, oldest first.
+	closed   bool       // closed is set once the queue no longer accepts entries.
+
+	enqueued  uint64        // enqueued is the number of entries accepted into the queue.
+	completed uint64        // completed is the number of accepted entries delivered or dropped.
+	waiters   []flushWaiter // waiters are the pending flush calls.
+
+	dropped atomic.Uint64 // dropped is the number of entries dropped by the overflow policy.
+	done    chan struct{} // done is closed when the worker has exited.
+}
+
+// flushWaiter is a pending flush call waiting for the entries that were
+// accepted before it to complete.
+type flushWaiter struct {
+	target uint64        // target is the completed count the waiter is waiting for.
+	done   chan struct{} // done is closed once target has been reached.
+}
+
+// newQueue creates a queue that delivers entries with the given function and
+// starts its worker.
+func newQueue(deliver func(entry *Entry), size int, overflow OverflowPolicy) *queue {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+
+	q := &queue{
+		deliver:  deliver,
+		size:     size,
+		overflow: overflow,
+		entries:  make([]*Entry, 0, size),
+		done:     make(chan struct{}),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+
+	go q.work()
+
+	return q
+}
+
+// enqueue adds an entry to the queue, applying the overflow policy when the
+// queue is full. Entries enqueued after the queue is closed are discarded.
+func (q *queue) enqueue(entry *Entry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.entries) >= q.size {
+		switch q.overflow {
+		case OverflowDropNewest:
+			q.dropped.Add(1)
+			return
+		case OverflowDropOldest:
+			q.entries[0] = nil
+			q.entries = q.entries[1:]
+			q.dropped.Add(1)
+			q.complete()
+		default:
+			q.notFull.Wait()
+		}
+	}
+
+	if q.closed {
+		return
+	}
+
+	q.entries = append(q.entries, entry)
+	q.enqueued++
+	q.notEmpty.Signal()
+}
+
+// flush waits until every entry accepted before the call has been delivered
+// or dropped.
+//
+// Returns:
+//   - `error` if the context is done before the queue has been flushed.
+//   - `nil` if the queue has been flushed.
+func (q *queue) flush(ctx context.Context) error {
+	q.mu.Lock()
+	if q.completed >= q.enqueued {
+		q.mu.Unlock()
+		return nil
+	}
+	w := flushWaiter{target: q.enqueued, done: make(chan struct{})}
+	q.waiters = append(q.waiters, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// complete records that an accepted entry has been delivered or dropped and
+// releases the flush calls waiting for it. The caller must hold q.mu.
+func (q *queue) complete() {
+	q.completed++
+
+	waiters := q.waiters[:0]
+	for _, w := range q.waiters {
+		if w.target <= q.completed {
+			close(w.done)
+		} else {
+			waiters = append(waiters, w)
+		}
+	}
+	q.waiters = waiters
+}
+
+// close stops the queue from accepting entries. The worker delivers any
+// entries that are still queued and then exits, closing q.done.
+func (q *queue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+}
+
+// work delivers queued entries until the queue is closed and empty.
+func (q *queue) work() {
+	defer close(q.done)
+
+	for {
+		q.mu.Lock()
+		for len(q.entries) == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if len(q.entries) == 0 {
+			q.mu.Unlock()
+			return
+		}
+		entry := q.entries[0]
+		q.entries[0] = nil
+		q.entries = q.entries[1:]
+		q.notFull.Signal()
+		q.mu.Unlock()
+
+		q.deliver(entry)
+
+		q.mu.Lock()
+		q.complete()
+		q.mu.Unlock()
+	}
+}
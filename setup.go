@@ -1,15 +1,57 @@
 package multilog
 
-import "fmt"
+import (
+	"context"
+	"sync/atomic"
+)
 
-// Loggers is a map of log methods to custom loggers.
+// std is the default Multilog used by the package-level functions.
+var std atomic.Pointer[Multilog]
+
+func init() {
+	std.Store(NewMultilog(nil))
+}
+
+// Default returns the default Multilog used by the package-level functions.
+//
+// Returns:
+//   - *Multilog: The default dispatcher.
+func Default() *Multilog {
+	return std.Load()
+}
+
+// SetDefault replaces the default Multilog used by the package-level functions.
+//
+// Arguments:
+//   - m: The dispatcher to use as the default.
+func SetDefault(m *Multilog) {
+	std.Store(m)
+}
+
+// SetLevel sets the minimum log level of the groups of the default Multilog
+// that have no level of their own.
+//
+// Arguments:
+//   - level: The new base level.
+func SetLevel(level LogLevel) {
+	Default().Levels().SetBase(level)
+}
+
+// SetGroupLevel sets the minimum log level of the groups of the default
+// Multilog that match a pattern, as described for Levels.
+//
+// Arguments:
+//   - pattern: The exact group, glob or "re:" regular expression to match.
+//   - level: The level of the matching groups.
 //
-// This is a global variable that is used to store the loggers for each log method.
-// It is not recommended to use this variable directly, but rather to use the functions
-// in the log package to register and retrieve loggers.
-var Loggers map[LogMethod]*CustomLogger = make(map[LogMethod]*CustomLogger)
+// Returns:
+//   - `error` if the pattern is empty or not a valid regular expression.
+//   - `nil` if the level was set.
+func SetGroupLevel(pattern string, level LogLevel) error {
+	return Default().Levels().Set(pattern, level)
+}
 
-// NewLogger creates a new logger for the given log method.
+// NewLogger creates a new logger for the given log method on the default Multilog.
 //
 // Arguments:
 //   - t: The log method to create a logger for.
@@ -17,31 +59,92 @@ var Loggers map[LogMethod]*CustomLogger = make(map[LogMethod]*CustomLogger)
 // Returns:
 //
 //   - A new logger for the given log method.
-//     If a logger for the given log method is already registered, it is returned.
-//     Otherwise, a new logger is created and registered for the given log method.
+//     Any logger previously registered for the given log method is replaced.
 func NewLogger(t LogMethod) *CustomLogger {
-	Loggers[t] = &CustomLogger{}
-	return Loggers[t]
+	return Default().NewLogger(t)
 }
 
-// RegisterLogger registers a custom logger for a given log method.
+// RegisterLogger registers a custom logger for a given log method on the default Multilog.
 //
 // Arguments:
 //   - t: The log method to register a logger for.
 //   - logger: The custom logger to register.
 //
 // Returns:
-//   - `error` if the logger for the given log method is already registered.
+//   - `error` if the logger for the given log method is already registered or
+//     its Setup function returns an error.
 //   - `nil` if the logger for the given log method is successfully registered.
 func RegisterLogger(t LogMethod, logger *CustomLogger) error {
-	if _, exists := Loggers[t]; exists {
-		return fmt.Errorf("logger for log method %s already registered", t)
-	}
+	return Default().RegisterLogger(t, logger)
+}
 
-	if logger.Setup != nil {
-		logger.Setup()
-	}
+// UnregisterLogger removes the logger registered for a given log method on the default Multilog.
+//
+// Arguments:
+//   - t: The log method to unregister.
+//
+// Returns:
+//   - `error` if no logger is registered for the given log method.
+//   - `nil` if the logger was successfully unregistered.
+func UnregisterLogger(t LogMethod) error {
+	return Default().UnregisterLogger(t)
+}
 
-	Loggers[t] = logger
-	return nil
+// ReplaceLogger registers a custom logger for a given log method on the default Multilog,
+// replacing any logger that is already registered for it.
+//
+// Arguments:
+//   - t: The log method to register a logger for.
+//   - logger: The custom logger to register.
+//
+// Returns:
+//   - `error` if the logger's Setup function returns an error, in which case
+//     the previous logger stays registered.
+//   - `nil` if the logger for the given log method is successfully replaced.
+func ReplaceLogger(t LogMethod, logger *CustomLogger) error {
+	return Default().ReplaceLogger(t, logger)
+}
+
+// Registered returns the log methods that currently have a registered logger on the default Multilog.
+//
+// Returns:
+//   - []LogMethod: The registered log methods in sorted order.
+func Registered() []LogMethod {
+	return Default().Registered()
+}
+
+// Dropped returns the number of messages dropped by the overflow policy for
+// each logger registered on the default Multilog.
+//
+// Returns:
+//   - map[LogMethod]uint64: The dropped message counts keyed by log method.
+func Dropped() map[LogMethod]uint64 {
+	return Default().Dropped()
+}
+
+// Flush waits for the queued messages of every logger registered on the default
+// Multilog to be delivered and then calls each logger's Flush function.
+//
+// Arguments:
+//   - ctx: The context that bounds how long Flush waits.
+//
+// Returns:
+//   - `error` joining the errors of every logger that failed to flush in time.
+//   - `nil` if every logger was flushed.
+func Flush(ctx context.Context) error {
+	return Default().Flush(ctx)
+}
+
+// Shutdown unregisters every logger registered on the default Multilog, waits
+// for their queued messages to be delivered and then calls each logger's Flush
+// and Close functions.
+//
+// Arguments:
+//   - ctx: The context that bounds how long Shutdown waits.
+//
+// Returns:
+//   - `error` joining the errors of every logger that failed to shut down in time.
+//   - `nil` if every logger was shut down.
+func Shutdown(ctx context.Context) error {
+	return Default().Shutdown(ctx)
 }
@@ -0,0 +1,280 @@
+package multilog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+// NewSlogHandlerArgs are the arguments for the NewSlogHandler function.
+type NewSlogHandlerArgs struct {
+	// Multilog is the dispatcher the records are routed to. Defaults to the
+	// default Multilog, resolved for every record.
+	Multilog *Multilog
+	// Group is the group of the records logged through a handler without a group.
+	Group string
+}
+
+// SlogHandler is a slog.Handler that routes slog records to the loggers
+// registered on a Multilog, so that libraries logging through log/slog reach
+// the same sinks as the rest of the program.
+//
+// Attributes added with WithAttrs are bound to every entry, and group
+// attributes become Object fields. Groups opened with WithGroup qualify the
+// attributes that follow them, which are nested into an Object field named
+// after the group and left out when there are none, and are also nested into
+// the group of the entries with a dot, as with ChildLogger.WithGroup, so that
+// group levels apply to them.
+//
+// Records without a time are dispatched as entries with a zero Time.
+type SlogHandler struct {
+	m      *Multilog   // m is the dispatcher, nil for the default Multilog.
+	group  string      // group is the group of the entries.
+	fields Fields      // fields are the fields bound with WithAttrs before the first WithGroup.
+	scopes []slogScope // scopes are the groups opened with WithGroup, outermost first.
+}
+
+// slogScope is a group opened with WithGroup and the fields bound with
+// WithAttrs while it was the innermost group.
+type slogScope struct {
+	name   string // name is the name of the group.
+	fields Fields // fields are the fields bound within the group.
+}
+
+// NewSlogHandler creates a slog.Handler that routes slog records to the loggers
+// registered on a Multilog.
+//
+// A SlogHandler must not be used by a logger registered on the Multilog it
+// routes to, such as a SlogHandlerLogger, or records would loop forever.
+//
+// Arguments:
+//   - args <*NewSlogHandlerArgs>: The arguments to create a new handler, nil uses the defaults.
+//
+// Returns:
+//   - *SlogHandler: The new handler.
+func NewSlogHandler(args *NewSlogHandlerArgs) *SlogHandler {
+	if args == nil {
+		args = &NewSlogHandlerArgs{}
+	}
+
+	return &SlogHandler{
+		m:     args.Multilog,
+		group: args.Group,
+	}
+}
+
+// Enabled implements slog.Handler, reporting whether the level is at or above
+// the level of the Multilog.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.multilog().levels.Enabled(LevelFromSlog(level), h.group)
+}
+
+// Handle implements slog.Handler, dispatching the record to every registered logger.
+// Errors from the loggers are reported to the Multilog's ErrorHandler, so Handle always returns nil.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(Fields, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, a)
+		return true
+	})
+
+	// The attributes of the record belong to the innermost group, and each
+	// group is wrapped into the one enclosing it, leaving out empty groups.
+	for i := len(h.scopes) - 1; i >= 0; i-- {
+		scope := h.scopes[i]
+		if len(scope.fields)+len(fields) == 0 {
+			continue
+		}
+		nested := make(Fields, 0, len(scope.fields)+len(fields))
+		nested = append(append(nested, scope.fields...), fields...)
+		fields = Fields{Object(scope.name, nested...)}
+	}
+	if len(h.fields) > 0 {
+		fields = append(append(make(Fields, 0, len(h.fields)+len(fields)), h.fields...), fields...)
+	}
+
+	// A zero time is left as it is, as the slog.Handler contract requires.
+	h.multilog().logAt(ctx, r.Time, LevelFromSlog(r.Level), h.group, r.Message, fields)
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler, returning a handler that adds the
+// attributes to every record, qualified by the groups opened on h.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	bound := h.fields
+	if len(h.scopes) > 0 {
+		bound = h.scopes[len(h.scopes)-1].fields
+	}
+	fields := make(Fields, 0, len(bound)+len(attrs))
+	fields = append(fields, bound...)
+	for _, a := range attrs {
+		fields = appendAttr(fields, a)
+	}
+
+	child := &SlogHandler{m: h.m, group: h.group, fields: h.fields, scopes: h.scopes}
+	if len(h.scopes) == 0 {
+		child.fields = fields
+		return child
+	}
+	child.scopes = append([]slogScope(nil), h.scopes...)
+	child.scopes[len(child.scopes)-1].fields = fields
+
+	return child
+}
+
+// WithGroup implements slog.Handler, returning a handler whose group is nested
+// under the group of h and that nests the attributes that follow into an
+// Object field named after the group.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	scopes := make([]slogScope, len(h.scopes), len(h.scopes)+1)
+	copy(scopes, h.scopes)
+	scopes = append(scopes, slogScope{name: name})
+
+	return &SlogHandler{m: h.m, group: joinGroup(h.group, name), fields: h.fields, scopes: scopes}
+}
+
+// multilog returns the dispatcher the handler routes to.
+func (h *SlogHandler) multilog() *Multilog {
+	if h.m == nil {
+		return Default()
+	}
+
+	return h.m
+}
+
+// appendAttr converts a slog attribute to a field, following the slog rules
+// that empty attributes are ignored and groups without a key are inlined.
+func appendAttr(fields Fields, a slog.Attr) Fields {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return append(fields, String(a.Key, a.Value.String()))
+	case slog.KindInt64:
+		return append(fields, Int64(a.Key, a.Value.Int64()))
+	case slog.KindUint64:
+		return append(fields, Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, Float64(a.Key, a.Value.Float64()))
+	case slog.KindBool:
+		return append(fields, Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, Duration(a.Key, a.Value.Duration()))
+	case slog.KindTime:
+		return append(fields, Time(a.Key, a.Value.Time()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, nested := range attrs {
+				fields = appendAttr(fields, nested)
+			}
+			return fields
+		}
+		nested := make(Fields, 0, len(attrs))
+		for _, attr := range attrs {
+			nested = appendAttr(nested, attr)
+		}
+		return append(fields, Object(a.Key, nested...))
+	default:
+		return append(fields, Any(a.Key, a.Value.Any()))
+	}
+}
+
+// fieldAttr converts a field to a slog attribute. Object fields become groups.
+func fieldAttr(f Field) slog.Attr {
+	switch f.Type {
+	case StringType:
+		return slog.String(f.Key, f.String)
+	case IntType:
+		return slog.Int64(f.Key, f.Integer)
+	case UintType:
+		return slog.Uint64(f.Key, uint64(f.Integer))
+	case ObjectType:
+		fields := f.Interface.(Fields)
+		attrs := make([]any, 0, len(fields))
+		for _, nested := range fields {
+			attrs = append(attrs, fieldAttr(nested))
+		}
+		return slog.Group(f.Key, attrs...)
+	default:
+		return slog.Any(f.Key, f.Value())
+	}
+}
+
+// NewSlogHandlerLoggerArgs are the arguments for the NewSlogHandlerLogger function.
+type NewSlogHandlerLoggerArgs struct {
+	// Level is the base of the levels of the returned CustomLogger, which
+	// group patterns set on its Levels can override in either direction.
+	Level LogLevel
+	// Handler is the slog.Handler the entries are forwarded to.
+	Handler slog.Handler
+}
+
+// SlogHandlerLogger is a custom logger that forwards entries to a slog.Handler.
+type SlogHandlerLogger struct {
+	args *NewSlogHandlerLoggerArgs // args are the arguments for the NewSlogHandlerLogger function.
+}
+
+// Setup checks that a handler was provided.
+func (l *SlogHandlerLogger) Setup() error {
+	if l.args.Handler == nil {
+		return errors.New("slog handler is required")
+	}
+
+	return nil
+}
+
+// Log converts the entry to a slog record and passes it to the handler. The
+// group is added as a "group" attribute, followed by the fields.
+func (l *SlogHandlerLogger) Log(entry *Entry) error {
+	ctx := context.Background()
+	level := entry.Level.SlogLevel()
+	if !l.args.Handler.Enabled(ctx, level) {
+		return nil
+	}
+
+	r := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	if entry.Group != "" {
+		r.AddAttrs(slog.String("group", entry.Group))
+	}
+	for _, f := range entry.Fields {
+		r.AddAttrs(fieldAttr(f))
+	}
+
+	return l.args.Handler.Handle(ctx, r)
+}
+
+// NewSlogHandlerLogger creates a new CustomLogger that forwards entries to an
+// existing slog.Handler, such as slog.NewJSONHandler or a third-party handler.
+//
+// Arguments:
+//   - args <*NewSlogHandlerLoggerArgs>: The arguments to create a new slog handler logger.
+//
+// Returns:
+//   - *CustomLogger: The custom logger.
+func NewSlogHandlerLogger(args *NewSlogHandlerLoggerArgs) *CustomLogger {
+	logger := &SlogHandlerLogger{
+		args: args,
+	}
+
+	return &CustomLogger{
+		Setup:  logger.Setup,
+		Log:    logger.Log,
+		Levels: NewLevels(args.Level),
+	}
+}
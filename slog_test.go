@@ -0,0 +1,131 @@
+package multilog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/slogtest"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var got []*Entry
+
+	m := NewMultilog(&NewMultilogArgs{Level: DEBUG})
+	if err := m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			got = append(got, entry)
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(NewSlogHandler(&NewSlogHandlerArgs{Multilog: m, Group: "lib"}))
+	logger.Debug("dropped", "level", "below DEBUG")
+	logger.With("request_id", "abc").WithGroup("auth").Warn("denied",
+		"attempt", 2,
+		slog.Group("user", "id", "bob", "admin", false),
+		slog.Group("", "inlined", true),
+		slog.Any("error", errors.New("bad token")),
+	)
+	slog.New(NewSlogHandler(&NewSlogHandlerArgs{Multilog: m})).Log(context.Background(), slog.LevelDebug-4, "trace")
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(got))
+	}
+	entry := got[0]
+	if entry.Level != DEBUG || entry.Group != "lib" {
+		t.Errorf("unexpected level or group: %d %s", entry.Level, entry.Group)
+	}
+	entry = got[1]
+	if entry.Level != WARN || entry.Group != "lib.auth" || entry.Message != "denied" {
+		t.Errorf("unexpected entry: %d %s %s", entry.Level, entry.Group, entry.Message)
+	}
+	want := "{request_id=abc auth={attempt=2 user={id=bob admin=false} inlined=true error=bad token}}"
+	if entry.Fields.Text() != want {
+		t.Errorf("expected fields %s, got %s", want, entry.Fields.Text())
+	}
+	if auth := entry.Fields[1].Interface.(Fields); auth[3].Type != ErrorType {
+		t.Errorf("expected the error attribute to become an error field")
+	}
+}
+
+func TestSlogHandler_Slogtest(t *testing.T) {
+	var entries []*Entry
+	m := NewMultilog(&NewMultilogArgs{Level: TRACE})
+	if err := m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			entries = append(entries, entry)
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	results := func() []map[string]any {
+		var records []map[string]any
+		for _, entry := range entries {
+			b, err := entry.Fields.AppendJSON(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			record := map[string]any{}
+			if err := json.Unmarshal(b, &record); err != nil {
+				t.Fatal(err)
+			}
+			record[slog.LevelKey] = entry.Level.String()
+			record[slog.MessageKey] = entry.Message
+			if !entry.Time.IsZero() {
+				record[slog.TimeKey] = entry.Time
+			}
+			records = append(records, record)
+		}
+		return records
+	}
+
+	if err := slogtest.TestHandler(NewSlogHandler(&NewSlogHandlerArgs{Multilog: m}), results); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSlogHandlerLogger(t *testing.T) {
+	b := bytes.Buffer{}
+	logger := NewSlogHandlerLogger(&NewSlogHandlerLoggerArgs{
+		Handler: slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: slog.LevelInfo}),
+	})
+	if err := logger.Setup(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	logger.Log(&Entry{Time: now, Level: DEBUG, Group: "api", Message: "dropped by the handler"})
+	logger.Log(&Entry{Time: now, Level: ERROR, Group: "api", Message: "failed", Fields: Fields{
+		Int("status", 500),
+		Object("user", String("id", "bob")),
+	}})
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d: %s", len(lines), b.String())
+	}
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != "ERROR" || record["msg"] != "failed" || record["group"] != "api" || record["status"] != 500.0 {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if user, _ := record["user"].(map[string]any); user["id"] != "bob" {
+		t.Errorf("expected the object field to become a group, got %v", record["user"])
+	}
+
+	if err := NewSlogHandlerLogger(&NewSlogHandlerLoggerArgs{}).Setup(); err == nil {
+		t.Error("expected an error setting up without a handler")
+	}
+}
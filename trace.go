@@ -0,0 +1,90 @@
+package multilog
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceFields is a ContextExtractor that returns the trace_id, span_id and
+// trace_flags of the OpenTelemetry span context carried by ctx, so that log
+// entries can be joined with the traces they were logged in.
+//
+// It is added ahead of NewMultilogArgs.ContextExtractors unless
+// NewMultilogArgs.DisableTraceFields is set.
+//
+// Arguments:
+//   - ctx: The context to read the span context from.
+//
+// Returns:
+//   - []Field: The trace correlation fields, or nil if ctx has no valid span context.
+func TraceFields(ctx context.Context) []Field {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nil
+	}
+
+	return []Field{
+		String("trace_id", sc.TraceID().String()),
+		String("span_id", sc.SpanID().String()),
+		String("trace_flags", sc.TraceFlags().String()),
+	}
+}
+
+// addSpanEvent records an entry as an event on the span carried by ctx, if
+// that span is recording.
+func addSpanEvent(ctx context.Context, entry *Entry) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	attributes := make([]attribute.KeyValue, 0, len(entry.Fields)+2)
+	attributes = append(attributes,
+		attribute.String("log.level", entry.Level.String()),
+		attribute.String("log.group", entry.Group),
+	)
+	attributes = appendAttributes(attributes, "", entry.Fields)
+
+	span.AddEvent(entry.Message, trace.WithTimestamp(entry.Time), trace.WithAttributes(attributes...))
+}
+
+// appendAttributes converts fields to span attributes, flattening nested
+// objects into dotted keys.
+func appendAttributes(attributes []attribute.KeyValue, prefix string, fields Fields) []attribute.KeyValue {
+	for _, f := range fields {
+		key := f.Key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch f.Type {
+		case StringType:
+			attributes = append(attributes, attribute.String(key, f.String))
+		case IntType:
+			attributes = append(attributes, attribute.Int64(key, f.Integer))
+		case UintType:
+			if uint64(f.Integer) > math.MaxInt64 {
+				attributes = append(attributes, attribute.String(key, strconv.FormatUint(uint64(f.Integer), 10)))
+			} else {
+				attributes = append(attributes, attribute.Int64(key, f.Integer))
+			}
+		case FloatType:
+			attributes = append(attributes, attribute.Float64(key, math.Float64frombits(uint64(f.Integer))))
+		case BoolType:
+			attributes = append(attributes, attribute.Bool(key, f.Integer == 1))
+		case DurationType:
+			attributes = append(attributes, attribute.String(key, time.Duration(f.Integer).String()))
+		case ObjectType:
+			attributes = appendAttributes(attributes, key, f.Interface.(Fields))
+		default:
+			attributes = append(attributes, attribute.String(key, f.Text()))
+		}
+	}
+
+	return attributes
+}
@@ -0,0 +1,87 @@
+package multilog
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingSpan is a span that records the events added to it.
+type recordingSpan struct {
+	noop.Span
+	sc     trace.SpanContext
+	events []string
+	attrs  [][]attribute.KeyValue
+}
+
+func (s *recordingSpan) IsRecording() bool { return true }
+
+func (s *recordingSpan) SpanContext() trace.SpanContext { return s.sc }
+
+func (s *recordingSpan) AddEvent(name string, options ...trace.EventOption) {
+	s.events = append(s.events, name)
+	config := trace.NewEventConfig(options...)
+	s.attrs = append(s.attrs, config.Attributes())
+}
+
+func TestMultilog_Trace(t *testing.T) {
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	span := &recordingSpan{sc: sc}
+	ctx := trace.ContextWithSpan(context.Background(), span)
+
+	var got *Entry
+	m := NewMultilog(&NewMultilogArgs{SpanEvents: true})
+	if err := m.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			got = entry
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	m.InfoCtx(ctx, "api", "handled")
+	want := "{trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 trace_flags=01}"
+	if got.Fields.Text() != want {
+		t.Errorf("expected fields %s, got %s", want, got.Fields.Text())
+	}
+	if len(span.events) != 0 {
+		t.Errorf("expected no span events below WARN, got %v", span.events)
+	}
+
+	m.ErrorCtx(ctx, "api", "failed", Int("status", 500), Object("user", String("id", "bob")))
+	if len(span.events) != 1 || span.events[0] != "failed" {
+		t.Fatalf("expected one span event, got %v", span.events)
+	}
+	attrs := attribute.NewSet(span.attrs[0]...)
+	if v, _ := attrs.Value("status"); v.AsInt64() != 500 {
+		t.Errorf("expected status attribute 500, got %v", v.Emit())
+	}
+	if v, _ := attrs.Value("user.id"); v.AsString() != "bob" {
+		t.Errorf("expected user.id attribute bob, got %v", v.Emit())
+	}
+
+	m.Info("api", "no context")
+	if len(got.Fields) != 0 {
+		t.Errorf("expected no fields without a span, got %s", got.Fields.Text())
+	}
+
+	disabled := NewMultilog(&NewMultilogArgs{DisableTraceFields: true})
+	disabled.RegisterLogger("memory", &CustomLogger{
+		Log: func(entry *Entry) error {
+			got = entry
+			return nil
+		},
+	})
+	disabled.InfoCtx(ctx, "api", "handled")
+	if len(got.Fields) != 0 {
+		t.Errorf("expected no trace fields when disabled, got %s", got.Fields.Text())
+	}
+}
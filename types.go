@@ -1,8 +1,20 @@
 package multilog
 
+import (
+	"context"
+	"time"
+)
+
 // LogFn is a function type that defines the signature for logging functions.
-// It takes a log level, group name, message, and additional data as arguments.
-type LogFn func(level LogLevel, group string, message string, v map[string]interface{})
+// It takes the entry to log, holding the log level, group name, message and
+// fields, and returns an error if the entry could not be written.
+//
+// The entry is shared between all registered loggers and must not be modified.
+type LogFn func(entry *Entry) error
+
+// SetupFn is a function type that defines the signature for the setup function
+// of a custom logger. It returns an error if the logger could not be initialized.
+type SetupFn func() error
 
 // LogMethod represents the method used for logging, such as console or elasticsearch.
 type LogMethod string
@@ -10,31 +22,61 @@ type LogMethod string
 // LogLevel represents the severity level of a log message.
 type LogLevel int
 
+// LifecycleFn is a function type that defines the signature for the optional
+// flush and close hooks of a custom logger.
+// It must return once the work is done or the context is done, whichever is first.
+type LifecycleFn func(ctx context.Context) error
+
 // CustomLogger is a struct that defines a custom logger with setup and log functions.
 type CustomLogger struct {
-	Setup func() // Setup is a function that initializes the custom logger.
-	Log   LogFn  // Log is a function that logs a message with a given log level, group, message, and additional data.
+	Setup SetupFn     // Setup is a function that initializes the custom logger.
+	Log   LogFn       // Log is a function that logs an entry.
+	Flush LifecycleFn // Flush is an optional function that writes out any buffered messages.
+	Close LifecycleFn // Close is an optional function that releases the custom logger's resources.
+
+	// Levels are the minimum log levels of the messages dispatched to the
+	// custom logger for each group, applied after the dispatcher's own levels.
+	// The built-in loggers set them from their Level argument, and they default
+	// to levels that enable every message when the logger is registered.
+	Levels *Levels
 }
 
+// Entry is a single log message as it is dispatched to the registered loggers.
+type Entry struct {
+	Time    time.Time // Time is when the message was logged.
+	Level   LogLevel  // Level is the severity level of the message.
+	Group   string    // Group is the group name of the message.
+	Message string    // Message is the log message.
+	Fields  Fields    // Fields are the fields logged with the message, in order.
+}
+
+// ErrorHandler is a function type that handles errors returned by a logger.
+// It receives the log method of the failing logger, the entry that could not
+// be written (nil when the error is not tied to an entry, for example when a
+// removed logger fails to close) and the error.
+type ErrorHandler func(method LogMethod, entry *Entry, err error)
+
 // Logger is an interface that defines the methods required for a logger.
 type Logger interface {
-	Setup()                                                  // Setup initializes the logger.
-	Log(level LogLevel, group string, message string, v any) // Log logs a message with a given log level, group, message, and additional data.
+	Setup() error           // Setup initializes the logger.
+	Log(entry *Entry) error // Log logs an entry.
 }
 
+// The log levels are four apart, like the slog levels, so that custom levels
+// registered with RegisterLevel can be placed between them.
 const (
 	// TRACE represents the trace log level.
 	TRACE LogLevel = LogLevel(0)
 	// DEBUG represents the debug log level.
-	DEBUG LogLevel = LogLevel(1)
+	DEBUG LogLevel = LogLevel(4)
 	// INFO represents the info log level.
-	INFO LogLevel = LogLevel(2)
+	INFO LogLevel = LogLevel(8)
 	// WARN represents the warn log level.
-	WARN LogLevel = LogLevel(3)
+	WARN LogLevel = LogLevel(12)
 	// ERROR represents the error log level.
-	ERROR LogLevel = LogLevel(4)
+	ERROR LogLevel = LogLevel(16)
 	// FATAL represents the fatal log level.
-	FATAL LogLevel = LogLevel(5)
+	FATAL LogLevel = LogLevel(20)
 )
 
 const (
@@ -43,3 +85,8 @@ const (
 	// LoggerElasticsearch represents the elasticsearch log method.
 	LoggerElasticsearch LogMethod = "elasticsearch"
 )
+
+// ContextExtractor is a function type that extracts fields from the context
+// passed to the *Ctx log functions, such as a request or tenant ID stored by
+// a middleware. It returns nil when the context holds nothing of interest.
+type ContextExtractor func(ctx context.Context) []Field
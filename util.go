@@ -3,3 +3,11 @@ package multilog
 func PtrString(s string) *string {
 	return &s
 }
+
+func PtrBool(b bool) *bool {
+	return &b
+}
+
+func PtrLevel(level LogLevel) *LogLevel {
+	return &level
+}
@@ -0,0 +1,113 @@
+package multilog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// NewWriterLoggerArgs are the arguments for the NewWriterLogger function.
+type NewWriterLoggerArgs struct {
+	// Level is the base of the levels of the returned CustomLogger, which
+	// group patterns set on its Levels can override in either direction.
+	Level LogLevel
+	// Writer is where the encoded entries are written to, such as a network connection.
+	Writer io.Writer
+	// Encoder encodes the entries. Defaults to JSONEncoder.
+	Encoder Encoder
+	// FilterDropPatterns is a slice of regex patterns to filter out log messages.
+	FilterDropPatterns []*string
+}
+
+// WriterLogger is a custom logger that encodes entries and writes them to an io.Writer.
+type WriterLogger struct {
+	args           *NewWriterLoggerArgs // args are the arguments for the NewWriterLogger function.
+	filterPatterns []*regexp.Regexp     // filterPatterns are the regex patterns to filter out log messages.
+
+	mu  sync.Mutex // mu serializes the writes so that entries are never interleaved.
+	buf []byte     // buf is the buffer entries are encoded into, reused between writes.
+}
+
+// Setup checks that a writer was provided and compiles the filter patterns.
+func (l *WriterLogger) Setup() error {
+	if l.args.Writer == nil {
+		return errors.New("writer is required")
+	}
+	if l.args.Encoder == nil {
+		l.args.Encoder = JSONEncoder{}
+	}
+
+	for _, pattern := range l.args.FilterDropPatterns {
+		if pattern != nil {
+			compiledPattern, err := regexp.Compile(*pattern)
+			if err != nil {
+				return fmt.Errorf("error compiling filter pattern: %w", err)
+			}
+			l.filterPatterns = append(l.filterPatterns, compiledPattern)
+		}
+	}
+
+	return nil
+}
+
+// Log encodes the entry and writes it to the writer in a single Write call.
+func (l *WriterLogger) Log(entry *Entry) error {
+	// Check if the message matches any of the filter drop patterns.
+	for _, pattern := range l.filterPatterns {
+		if pattern.MatchString(entry.Group) || pattern.MatchString(entry.Message) {
+			return nil
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var err error
+	l.buf, err = l.args.Encoder.Encode(l.buf[:0], entry)
+	if err != nil {
+		return err
+	}
+	if _, err := l.args.Writer.Write(l.buf); err != nil {
+		return fmt.Errorf("error writing entry: %w", err)
+	}
+
+	return nil
+}
+
+// Flush flushes the writer when it is buffered, that is when it has a
+// Flush() error method such as *bufio.Writer.
+func (l *WriterLogger) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if w, ok := l.args.Writer.(interface{ Flush() error }); ok {
+		return w.Flush()
+	}
+
+	return nil
+}
+
+// NewWriterLogger creates a new CustomLogger that encodes entries with an
+// Encoder and writes them to an io.Writer. The writer is not closed when the
+// logger is closed.
+//
+// Arguments:
+//   - args <*NewWriterLoggerArgs>: The arguments to create a new writer logger.
+//
+// Returns:
+//   - *CustomLogger: The custom logger.
+func NewWriterLogger(args *NewWriterLoggerArgs) *CustomLogger {
+	logger := &WriterLogger{
+		args: args,
+	}
+
+	return &CustomLogger{
+		Setup:  logger.Setup,
+		Log:    logger.Log,
+		Flush:  logger.Flush,
+		Levels: NewLevels(args.Level),
+	}
+}